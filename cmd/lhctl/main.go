@@ -4,13 +4,19 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/auth"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/filecoin"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/storage"
 )
 
 func valueOrZero(p *int) int {
@@ -47,35 +53,106 @@ func progressBar(percent int, width int) string {
 	return fmt.Sprintf("[%s]", bar)
 }
 
+// humanBytes formats n in binary units, e.g. 1.5 GiB.
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 func main() {
 	ctx := context.Background()
 	apiKey := os.Getenv("LIGHTHOUSE_API_KEY")
-	if apiKey == "" {
-		log.Fatal("set LIGHTHOUSE_API_KEY env var")
-	}
 
-	upload := flag.String("upload", "", "file path to upload")
+	upload := flag.String("upload", "", "file path to upload, or - to read from stdin")
+	name := flag.String("name", "stdin", "with --upload -: filename to store the upload under")
+	resume := flag.Bool("resume", false, "with --upload: upload in chunks, resuming an interrupted upload of the same file")
 	info := flag.String("info", "", "CID to fetch info for")
 	list := flag.Bool("list", false, "list uploaded files")
 	lastKey := flag.String("last-key", "", "pagination cursor for --list")
+	all := flag.Bool("all", false, "with --list: walk every page")
 	deals := flag.String("deals", "", "CID to fetch Filecoin deal status")
+	usageFlag := flag.Bool("usage", false, "show storage used and remaining quota")
+	watch := flag.Bool("watch", false, "with --deals: poll until deals are active, printing each change")
+	replicas := flag.Int("replicas", 1, "with --deals --watch: number of active deals to wait for")
+	watchTimeout := flag.Duration("timeout", 0, "with --deals --watch: give up after this long (e.g. 2h)")
+	expiring := flag.String("expiring-within", "", "deal expiry report: list deals of all files expiring within this window (e.g. 30d, 72h)")
+	network := flag.String("network", "mainnet", "Filecoin network for epoch times: mainnet or calibnet")
 	del := flag.String("delete", "", "file ID to delete (use --list to find IDs)")
+	get := flag.String("get", "", "CID or /ipfs/, /ipns/ path to download from the gateway")
+	out := flag.String("out", "", "with --get: destination file (default stdout)")
+	verify := flag.Bool("verify", false, "with --get: fetch as a CAR and verify every block against the CID")
 
 	ipnsGenerate := flag.String("ipns-generate", "", "generate IPNS key with given name")
 	ipnsPublish := flag.String("ipns-publish", "", "publish CID to IPNS key (format: cid:keyName)")
 	ipnsList := flag.Bool("ipns-list", false, "list all IPNS keys")
 	ipnsRemove := flag.String("ipns-remove", "", "remove IPNS key by name")
+	createKey := flag.Bool("create-api-key", false, "mint an API key for the wallet in LIGHTHOUSE_PRIVATE_KEY")
+	verbose := flag.Bool("verbose", false, "log HTTP requests and retries to stderr")
 
 	flag.Parse()
 
-	cli := lighthouse.NewClient(nil, lighthouse.WithAPIKey(apiKey))
+	if apiKey == "" && !*createKey {
+		log.Fatal("set LIGHTHOUSE_API_KEY env var")
+	}
+
+	opts := []lighthouse.Option{lighthouse.WithAPIKey(apiKey)}
+	if *verbose {
+		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		opts = append(opts, lighthouse.WithLogger(logger))
+	}
+	cli := lighthouse.NewClient(nil, opts...)
 
 	switch {
+	case *createKey:
+		signer, err := auth.NewKeySigner(os.Getenv("LIGHTHOUSE_PRIVATE_KEY"))
+		if err != nil {
+			log.Fatal("LIGHTHOUSE_PRIVATE_KEY: ", err)
+		}
+		key, err := cli.Auth().CreateAPIKey(ctx, signer)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Wallet:  %s\n", signer.Address())
+		fmt.Printf("API key: %s\n", key)
+
 	case *upload != "":
 		startTime := time.Now()
 		var lastPercent float64
 
-		res, err := cli.Storage().UploadFile(ctx, *upload, schema.WithProgress(func(p schema.Progress) {
+		uploadFn := cli.Storage().UploadFile
+		switch {
+		case *upload == "-" && *resume:
+			log.Fatal("--resume needs a file, not stdin")
+		case *upload == "-":
+			uploadFn = func(ctx context.Context, _ string, opts ...schema.UploadOption) (*schema.UploadResult, error) {
+				return cli.Storage().Upload(ctx, storage.StreamSource(*name, os.Stdin, -1), opts...)
+			}
+		case *resume:
+			uploadFn = cli.Storage().ResumeUpload
+		}
+		var (
+			lastPrint time.Time
+			streaming bool
+		)
+		res, err := uploadFn(ctx, *upload, schema.WithProgress(func(p schema.Progress) {
+			// A stream's last update carries its total; always print it.
+			if p.Indeterminate() || streaming {
+				streaming = true
+				if !p.Indeterminate() || time.Since(lastPrint) >= 200*time.Millisecond {
+					speed := float64(p.Uploaded) / time.Since(startTime).Seconds() / 1024 / 1024
+					fmt.Printf("\r%s sent %.2f MB/s", humanBytes(p.Uploaded), speed)
+					lastPrint = time.Now()
+				}
+				return
+			}
 			percent := p.Percent()
 			if percent-lastPercent >= 1.0 || percent >= 100.0 {
 				elapsed := time.Since(startTime).Seconds()
@@ -98,6 +175,32 @@ func main() {
 		fmt.Printf("CID %s\n", res.Hash)
 		fmt.Printf("Time: %.2fs\n", time.Since(startTime).Seconds())
 
+	case *get != "":
+		var dlOpts []schema.DownloadOption
+		if *verify {
+			dlOpts = append(dlOpts, schema.WithVerifiedDownload())
+		}
+		if *out == "" {
+			getFn := cli.Gateway().Get
+			if *verify {
+				getFn = cli.Gateway().GetVerified
+			}
+			body, _, err := getFn(ctx, *get, "")
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer body.Close()
+			if _, err := io.Copy(os.Stdout, body); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+		gi, err := cli.Gateway().Download(ctx, *get, *out, dlOpts...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Saved %s (%d bytes, %s)\n", *out, gi.Size, gi.ContentType)
+
 	case *info != "":
 		i, err := cli.Files().Info(ctx, *info)
 		if err != nil {
@@ -105,6 +208,15 @@ func main() {
 		}
 		fmt.Printf("Name=%s  Size=%v CID=%s MimeType=%s Encryption=%v\n", i.FileName, i.FileSizeInBytes, i.CID, i.MimeType, i.Encryption)
 
+	case *list && *all:
+		fmt.Println("CID\tID\tSIZE(bytes)\tNAME")
+		for f, err := range cli.Files().All(ctx, schema.WithPrefetch(1)) {
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("%s\t%s\t%d\t%s\n", f.CID, f.ID, f.Size, f.Name)
+		}
+
 	case *list:
 		var cursor *string
 		if *lastKey != "" {
@@ -120,6 +232,64 @@ func main() {
 			fmt.Printf("%s\t%s\t%d\t%s\n", f.CID, f.ID, f.Size, f.Name)
 		}
 
+	case *deals != "" && *watch:
+		var wopts []schema.WatchOption
+		if *replicas > 0 {
+			wopts = append(wopts, schema.WithReplicas(*replicas))
+		}
+		if *watchTimeout > 0 {
+			wopts = append(wopts, schema.WithWatchTimeout(*watchTimeout))
+		}
+		fmt.Printf("Watching deals for %s (Ctrl-C to stop)\n", *deals)
+		for u, err := range cli.Deals().Watch(ctx, *deals, wopts...) {
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("\n[%s] %s, %d active replica(s)\n", u.Time.Format("15:04:05"), u.Phase, u.ActiveReplicas)
+			if len(u.Deals) > 0 {
+				printDeals(u.Deals)
+			}
+		}
+		fmt.Println("\nDeals active.")
+
+	case *expiring != "":
+		within, err := parseWithin(*expiring)
+		if err != nil {
+			log.Fatal(err)
+		}
+		net, ok := filecoin.NetworkByName(*network)
+		if !ok {
+			log.Fatalf("unknown network %q", *network)
+		}
+		cutoff := time.Now().Add(within)
+
+		fmt.Printf("%-62s %-30s %-20s %-20s %s\n", "CID", "Name", "Provider", "Expires", "Remaining")
+		fmt.Println(strings.Repeat("-", 150))
+		found := 0
+		for f, err := range cli.Files().All(ctx, schema.WithPrefetch(1)) {
+			if err != nil {
+				log.Fatal(err)
+			}
+			ds, err := cli.Deals().Status(ctx, f.CID)
+			if err != nil {
+				log.Printf("%s: %v", f.CID, err)
+				continue
+			}
+			for _, d := range ds {
+				exp := d.ExpiresAtOn(net)
+				if exp.IsZero() || exp.After(cutoff) {
+					continue
+				}
+				remaining := "expired"
+				if left := d.RemainingOn(net); left > 0 {
+					remaining = fmt.Sprintf("%.1fd", left.Hours()/24)
+				}
+				fmt.Printf("%-62s %-30s %-20s %-20s %s\n", f.CID, f.Name, d.StorageProvider, exp.Format("2006-01-02 15:04"), remaining)
+				found++
+			}
+		}
+		fmt.Printf("\n%d deal(s) expiring within %s.\n", found, *expiring)
+
 	case *deals != "":
 		ds, err := cli.Deals().Status(ctx, *deals)
 		if err != nil {
@@ -132,25 +302,21 @@ func main() {
 		}
 
 		fmt.Printf("Found %d deal(s):\n\n", len(ds))
-		fmt.Printf("%-30s %-20s %-50s %s\n", "Provider", "Status", "PieceCID", "ChainDealID")
-		fmt.Println(strings.Repeat("-", 120))
+		printDeals(ds)
 
-		for _, d := range ds {
-			provider := d.StorageProvider
-			if provider == "" {
-				provider = "(empty)"
-			}
-			status := d.DealStatus
-			if status == "" {
-				status = "(empty)"
-			}
-			pieceCID := d.PieceCID
-			if pieceCID == "" {
-				pieceCID = "(empty)"
-			}
-
-			fmt.Printf("%-30s %-20s %-50s %d\n", provider, status, pieceCID, d.ChainDealID)
+	case *usageFlag:
+		u, err := cli.User().Usage(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		percent := 0.0
+		if u.DataLimit > 0 {
+			percent = float64(u.DataUsed) * 100 / float64(u.DataLimit)
 		}
+		fmt.Printf("Used:      %s of %s (%.1f%%)\n", humanBytes(u.DataUsed), humanBytes(u.DataLimit), percent)
+		fmt.Printf("Remaining: %s\n", humanBytes(u.Remaining()))
+		fmt.Println(progressBar(int(percent), 40))
+
 	case *del != "":
 		if err := cli.Files().Delete(ctx, *del); err != nil {
 			log.Fatal(err)
@@ -216,14 +382,66 @@ func main() {
 	}
 }
 
+// parseWithin parses a duration that may use a day suffix, e.g. 30d.
+func parseWithin(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// printDeals renders deals as a table.
+func printDeals(ds []schema.DealStatus) {
+	fmt.Printf("%-30s %-20s %-50s %s\n", "Provider", "Status", "PieceCID", "ChainDealID")
+	fmt.Println(strings.Repeat("-", 120))
+
+	for _, d := range ds {
+		provider := d.StorageProvider
+		if provider == "" {
+			provider = "(empty)"
+		}
+		status := d.DealStatus
+		if status == "" {
+			status = "(empty)"
+		}
+		pieceCID := d.PieceCID
+		if pieceCID == "" {
+			pieceCID = "(empty)"
+		}
+
+		fmt.Printf("%-30s %-20s %-50s %d\n", provider, status, pieceCID, d.ChainDealID)
+	}
+}
+
 func usage() {
 	fmt.Println(`Usage:
   lhctl --upload <path>                 Upload a file (shows progress)
+  lhctl --upload <path> --resume        Chunked upload that resumes if interrupted
+  lhctl --upload - [--name <file>]      Upload stdin as it streams in (e.g. pg_dump | lhctl --upload -)
+  lhctl --get <cid|path> [--out <file>] Download from the gateway (stdout if no --out)
+  lhctl --get <cid> --verify            Verify every block against the CID while downloading
   lhctl --info <cid>                    Fetch file info by CID
   lhctl --list [--last-key <cursor>]    List uploaded files (shows IDs)
+  lhctl --list --all                    List every uploaded file across all pages
   lhctl --deals <cid>                   Show Filecoin deal status for a CID
+  lhctl --deals <cid> --watch [--replicas n] [--timeout d]
+                                        Wait for deals to become active, showing each change
+  lhctl --expiring-within <30d> [--network calibnet]
+                                        Deal expiry report: deals of all files expiring
+                                        within a window (a top-level flag, not --deals)
   lhctl --delete <id>                   Delete a file by ID (from --list)
+  lhctl --create-api-key                Mint an API key by signing with LIGHTHOUSE_PRIVATE_KEY
+  lhctl --usage                         Show storage used and remaining quota
+
+Flags:
+  --network mainnet|calibnet            Network used to convert deal epochs to dates
+  --verbose                             Log HTTP requests and retries to stderr
 
 Environment:
-  LIGHTHOUSE_API_KEY  API key for authenticated endpoints`)
+  LIGHTHOUSE_API_KEY      API key for authenticated endpoints
+  LIGHTHOUSE_PRIVATE_KEY  Hex wallet key for --create-api-key`)
 }
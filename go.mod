@@ -1,3 +1,10 @@
 module github.com/lighthouse-web3/lighthouse-go-sdk
 
 go 1.23.6
+
+require (
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.4.0
+	golang.org/x/crypto v0.36.0
+)
+
+require golang.org/x/sys v0.31.0 // indirect
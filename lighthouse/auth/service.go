@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"encoding/hex"
+	"net/url"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/cfg"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/httpx"
+)
+
+type Service struct {
+	h   *httpx.Client
+	cfg cfg.Config
+}
+
+func New(h *httpx.Client, c cfg.Config) *Service {
+	return &Service{h: h, cfg: c}
+}
+
+// Message fetches the one-time message a wallet must sign to obtain an API
+// key.
+func (s *Service) Message(ctx context.Context, address string) (_ string, err error) {
+	ctx, op := s.h.StartOp(ctx, "auth.Message")
+	defer func() { op.End(err) }()
+
+	u := s.cfg.Hosts.API + "/api/auth/get_message?publicKey=" + url.QueryEscape(address)
+	var msg string
+	_, err = s.h.WriteJSON(ctx, "GET", u, nil, &msg)
+	return msg, err
+}
+
+// ExchangeSignature trades a signed auth message for a new API key.
+// signature is the 0x-prefixed hex signature.
+func (s *Service) ExchangeSignature(ctx context.Context, address, signature string) (_ string, err error) {
+	ctx, op := s.h.StartOp(ctx, "auth.ExchangeSignature")
+	defer func() { op.End(err) }()
+
+	u := s.cfg.Hosts.API + "/api/auth/create_api_key"
+	body := map[string]string{"publicKey": address, "signedMessage": signature}
+	var key string
+	_, err = s.h.WriteJSON(ctx, "POST", u, body, &key)
+	return key, err
+}
+
+// CreateAPIKey runs the whole flow for signer's wallet: fetch the message,
+// sign it and exchange the signature for an API key.
+func (s *Service) CreateAPIKey(ctx context.Context, signer Signer) (string, error) {
+	addr := signer.Address()
+	msg, err := s.Message(ctx, addr)
+	if err != nil {
+		return "", err
+	}
+	sig, err := signer.SignMessage(ctx, []byte(msg))
+	if err != nil {
+		return "", err
+	}
+	return s.ExchangeSignature(ctx, addr, "0x"+hex.EncodeToString(sig))
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"golang.org/x/crypto/sha3"
+)
+
+// Signer signs the Lighthouse auth message on behalf of a wallet. Hardware
+// wallets, KMS keys and remote signers can implement it directly.
+type Signer interface {
+	// Address returns the 0x-prefixed wallet address.
+	Address() string
+	// SignMessage returns an EIP-191 personal_sign signature (r||s||v,
+	// v = 27 or 28) over message.
+	SignMessage(ctx context.Context, message []byte) ([]byte, error)
+}
+
+// KeySigner is a Signer backed by an in-memory secp256k1 private key.
+type KeySigner struct {
+	key  *secp256k1.PrivateKey
+	addr string
+}
+
+var _ Signer = (*KeySigner)(nil)
+
+// NewKeySigner parses a hex private key, with or without a 0x prefix.
+func NewKeySigner(hexKey string) (*KeySigner, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"))
+	if err != nil {
+		return nil, errors.New("auth: private key is not valid hex")
+	}
+	var scalar secp256k1.ModNScalar
+	if len(b) != 32 || scalar.SetByteSlice(b) || scalar.IsZero() {
+		return nil, errors.New("auth: invalid secp256k1 private key")
+	}
+	k := secp256k1.NewPrivateKey(&scalar)
+	h := keccak256(k.PubKey().SerializeUncompressed()[1:])
+	return &KeySigner{key: k, addr: checksumAddress(h[12:])}, nil
+}
+
+func (s *KeySigner) Address() string { return s.addr }
+
+func (s *KeySigner) SignMessage(_ context.Context, message []byte) ([]byte, error) {
+	h := HashMessage(message)
+	// SignCompact yields v||r||s with v = 27 + recovery ID and a low s
+	// (RFC 6979 nonces); Ethereum wants r||s||v.
+	compact := ecdsa.SignCompact(s.key, h[:], false)
+	return append(compact[1:], compact[0]), nil
+}
+
+// HashMessage returns the EIP-191 personal message hash of message.
+func HashMessage(message []byte) [32]byte {
+	prefix := "\x19Ethereum Signed Message:\n" + strconv.Itoa(len(message))
+	return keccak256([]byte(prefix), message)
+}
+
+// checksumAddress formats a 20-byte address with the EIP-55 mixed-case
+// checksum.
+func checksumAddress(addr []byte) string {
+	lower := hex.EncodeToString(addr)
+	h := keccak256([]byte(lower))
+	out := []byte(lower)
+	for i, c := range out {
+		if c >= 'a' && h[i/2]>>(4*(1-uint(i)%2))&0xf >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
+
+// keccak256 is the legacy (pre-FIPS) Keccak-256 Ethereum uses.
+func keccak256(data ...[]byte) [32]byte {
+	d := sha3.NewLegacyKeccak256()
+	for _, b := range data {
+		d.Write(b)
+	}
+	var out [32]byte
+	d.Sum(out[:0])
+	return out
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+)
+
+// Vectors from the web3.js accounts documentation.
+const (
+	testKey     = "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testAddress = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+)
+
+func TestKeccak256(t *testing.T) {
+	for in, want := range map[string]string{
+		"":    "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470",
+		"abc": "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45",
+	} {
+		h := keccak256([]byte(in))
+		if got := hex.EncodeToString(h[:]); got != want {
+			t.Errorf("keccak256(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestKeySignerAddress(t *testing.T) {
+	s, err := NewKeySigner(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Address(); got != testAddress {
+		t.Errorf("Address() = %s, want %s", got, testAddress)
+	}
+}
+
+func TestNewKeySignerRejectsInvalidKeys(t *testing.T) {
+	for _, k := range []string{
+		"",
+		"zz",
+		"0x00",
+		"0x0000000000000000000000000000000000000000000000000000000000000000",
+		// the curve order N
+		"0xfffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141",
+	} {
+		if _, err := NewKeySigner(k); err == nil {
+			t.Errorf("NewKeySigner(%q) succeeded", k)
+		}
+	}
+}
+
+func TestSignMessage(t *testing.T) {
+	s, err := NewKeySigner(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := s.SignMessage(context.Background(), []byte("Some data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const want = "b91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd" +
+		"6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a029" + "1c"
+	if got := hex.EncodeToString(sig); got != want {
+		t.Fatalf("signature\n got %s\nwant %s", got, want)
+	}
+
+	// v is 27 or 28 and s is in the lower half of the curve order.
+	halfN, _ := hex.DecodeString("7fffffffffffffffffffffffffffffff5d576e7357a4501ddfe92f46681b20a0")
+	for _, msg := range []string{"", "a", "Some data", "lighthouse"} {
+		sig, err := s.SignMessage(context.Background(), []byte(msg))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sig) != 65 || (sig[64] != 27 && sig[64] != 28) {
+			t.Errorf("%q: bad signature length or v: %x", msg, sig)
+		}
+		if string(sig[32:64]) > string(halfN) {
+			t.Errorf("%q: s is not low: %x", msg, sig[32:64])
+		}
+	}
+}
+
+func TestHashMessage(t *testing.T) {
+	h := HashMessage([]byte("Hello World"))
+	const want = "a1de988600a42c4b4ab089b619297c17d53cffae5d5120d82d8a92d0bb3b78f2"
+	if got := hex.EncodeToString(h[:]); got != want {
+		t.Errorf("HashMessage = %s, want %s", got, want)
+	}
+}
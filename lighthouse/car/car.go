@@ -0,0 +1,107 @@
+// Package car reads and writes Content Addressable aRchives (CARv1 and
+// CARv2) so content can be packed locally, with a known root CID, before it
+// is handed to Lighthouse for Filecoin deals.
+package car
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+)
+
+// Header is the CAR header. Version is the container version (1 or 2).
+type Header struct {
+	Version int
+	Roots   []cid.CID
+}
+
+// v2Pragma is the fixed CARv2 prefix: a CARv1-style header {"version": 2}.
+var v2Pragma = []byte{0x0a, 0xa1, 0x67, 'v', 'e', 'r', 's', 'i', 'o', 'n', 0x02}
+
+const v2HeaderSize = 40
+
+var (
+	ErrInvalidHeader = errors.New("car: invalid header")
+	ErrBlockMismatch = errors.New("car: block does not match its CID")
+	ErrMissingRoot   = errors.New("car: root block not present")
+)
+
+// Writer writes a CARv1 stream. Blocks already written are skipped.
+type Writer struct {
+	w    io.Writer
+	n    int64
+	seen map[string]bool
+}
+
+// NewWriter writes the CARv1 header for roots and returns a Writer for the blocks.
+func NewWriter(w io.Writer, roots ...cid.CID) (*Writer, error) {
+	cw := &Writer{w: w, seen: map[string]bool{}}
+	if err := cw.section(encodeHeader(roots)); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// Put appends a block section.
+func (w *Writer) Put(b cid.Block) error {
+	key := string(b.CID.Bytes())
+	if w.seen[key] {
+		return nil
+	}
+	w.seen[key] = true
+	return w.section(b.CID.Bytes(), b.Data)
+}
+
+// Size returns the number of bytes written so far.
+func (w *Writer) Size() int64 { return w.n }
+
+func (w *Writer) section(parts ...[]byte) error {
+	var l int
+	for _, p := range parts {
+		l += len(p)
+	}
+	buf := binary.AppendUvarint(nil, uint64(l))
+	for _, p := range parts {
+		buf = append(buf, p...)
+	}
+	n, err := w.w.Write(buf)
+	w.n += int64(n)
+	return err
+}
+
+// encodeHeader returns the DAG-CBOR encoding of {"roots": [...], "version": 1}.
+func encodeHeader(roots []cid.CID) []byte {
+	b := []byte{0xa2}
+	b = appendCBORText(b, "roots")
+	b = appendCBORHead(b, 4, uint64(len(roots)))
+	for _, r := range roots {
+		link := append([]byte{0x00}, r.Bytes()...)
+		b = append(b, 0xd8, 0x2a) // tag 42: CID
+		b = appendCBORHead(b, 2, uint64(len(link)))
+		b = append(b, link...)
+	}
+	b = appendCBORText(b, "version")
+	return appendCBORHead(b, 0, 1)
+}
+
+func appendCBORText(b []byte, s string) []byte {
+	b = appendCBORHead(b, 3, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	m := major << 5
+	switch {
+	case n < 24:
+		return append(b, m|byte(n))
+	case n <= 0xff:
+		return append(b, m|24, byte(n))
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(b, m|25), uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(b, m|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, m|27), n)
+}
@@ -0,0 +1,178 @@
+package car
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+)
+
+func testBlocks() []cid.Block {
+	var out []cid.Block
+	for _, s := range []string{"alpha", "beta", "gamma"} {
+		out = append(out, cid.Block{CID: cid.Sum(cid.Raw, []byte(s)), Data: []byte(s)})
+	}
+	return out
+}
+
+// writeV1 returns a CARv1 holding blocks, each written twice to exercise
+// deduplication, rooted at the first block.
+func writeV1(t *testing.T, blocks []cid.Block) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, blocks[0].CID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		for _, b := range blocks {
+			if err := w.Put(b); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if w.Size() != int64(buf.Len()) {
+		t.Errorf("Size() = %d, wrote %d", w.Size(), buf.Len())
+	}
+	return buf.Bytes()
+}
+
+func readAll(t *testing.T, r io.Reader) (Header, []cid.Block) {
+	t.Helper()
+	cr, err := NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []cid.Block
+	for {
+		b, err := cr.Next()
+		if err == io.EOF {
+			return cr.Header(), out
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, b)
+	}
+}
+
+func checkBlocks(t *testing.T, got, want []cid.Block) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d blocks, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].CID.Equal(want[i].CID) || !bytes.Equal(got[i].Data, want[i].Data) {
+			t.Errorf("block %d = %s %q, want %s %q", i, got[i].CID, got[i].Data, want[i].CID, want[i].Data)
+		}
+	}
+}
+
+func TestRoundTripV1(t *testing.T) {
+	blocks := testBlocks()
+	h, got := readAll(t, bytes.NewReader(writeV1(t, blocks)))
+	if h.Version != 1 || len(h.Roots) != 1 || !h.Roots[0].Equal(blocks[0].CID) {
+		t.Errorf("header = %+v", h)
+	}
+	checkBlocks(t, got, blocks)
+}
+
+// A CARv2 payload may start after padding, and the index that follows it
+// must not be read as blocks.
+func TestReaderV2Offsets(t *testing.T) {
+	blocks := testBlocks()
+	v1 := writeV1(t, blocks)
+	const padding = 17
+	offset := uint64(len(v2Pragma) + v2HeaderSize + padding)
+
+	var b []byte
+	b = append(b, v2Pragma...)
+	b = append(b, make([]byte, 16)...)
+	b = binary.LittleEndian.AppendUint64(b, offset)
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(v1)))
+	b = binary.LittleEndian.AppendUint64(b, offset+uint64(len(v1)))
+	b = append(b, make([]byte, padding)...)
+	b = append(b, v1...)
+	b = append(b, "trailing index bytes"...)
+
+	h, got := readAll(t, bytes.NewReader(b))
+	if h.Version != 2 || !h.Roots[0].Equal(blocks[0].CID) {
+		t.Errorf("header = %+v", h)
+	}
+	checkBlocks(t, got, blocks)
+}
+
+func TestReaderV2BadOffset(t *testing.T) {
+	var b []byte
+	b = append(b, v2Pragma...)
+	b = append(b, make([]byte, 16)...)
+	b = binary.LittleEndian.AppendUint64(b, 8) // inside the v2 header
+	b = binary.LittleEndian.AppendUint64(b, 0)
+	b = binary.LittleEndian.AppendUint64(b, 0)
+	if _, err := NewReader(bytes.NewReader(b)); !errors.Is(err, ErrInvalidHeader) {
+		t.Errorf("err = %v, want ErrInvalidHeader", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	blocks := testBlocks()
+	if _, err := Validate(bytes.NewReader(writeV1(t, blocks))); err != nil {
+		t.Errorf("valid car: %v", err)
+	}
+
+	tampered := writeV1(t, blocks)
+	i := bytes.LastIndex(tampered, []byte("gamma"))
+	tampered[i] = 'G'
+	if _, err := Validate(bytes.NewReader(tampered)); !errors.Is(err, ErrBlockMismatch) {
+		t.Errorf("tampered block: err = %v, want ErrBlockMismatch", err)
+	}
+
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, cid.Sum(cid.Raw, []byte("absent")))
+	w.Put(blocks[0])
+	if _, err := Validate(&buf); !errors.Is(err, ErrMissingRoot) {
+		t.Errorf("missing root: err = %v, want ErrMissingRoot", err)
+	}
+}
+
+// Create must produce the root an upload of the same file gets, in both
+// container versions.
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "data.bin")
+	data := bytes.Repeat([]byte("lighthouse "), 30000)
+	if err := os.WriteFile(src, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want, err := cid.Compute(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []int{1, 2} {
+		dst := filepath.Join(dir, "out.car")
+		root, err := Create(dst, src, Options{Version: v})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !root.Equal(want) {
+			t.Errorf("v%d: root %s, want %s", v, root, want)
+		}
+		f, err := os.Open(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, err := Validate(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("v%d: %v", v, err)
+		}
+		if h.Version != v || !h.Roots[0].Equal(want) {
+			t.Errorf("v%d: header = %+v", v, h)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package car
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/unixfs"
+)
+
+type Options struct {
+	Version   int // 1 (default) or 2
+	ChunkSize int // default cid.DefaultChunkSize
+}
+
+// Create packs the file or directory at src into a CAR at dst and returns
+// its root CID, which is the CID an /api/v0/add?cid-version=1 upload of src
+// yields. Directories become basic UnixFS directories (no HAMT sharding),
+// symlinks are stored as UnixFS symlinks. dst is written atomically.
+func Create(dst, src string, opts Options) (_ cid.CID, err error) {
+	if opts.Version == 0 {
+		opts.Version = 1
+	}
+	if opts.Version != 1 && opts.Version != 2 {
+		return cid.CID{}, fmt.Errorf("car: unsupported version %d", opts.Version)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".car-*.tmp")
+	if err != nil {
+		return cid.CID{}, err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	var prefix int64
+	if opts.Version == 2 {
+		// Reserve the pragma and header; filled in once the payload size is known.
+		if _, err := tmp.Write(make([]byte, len(v2Pragma)+v2HeaderSize)); err != nil {
+			return cid.CID{}, err
+		}
+		prefix = int64(len(v2Pragma) + v2HeaderSize)
+	}
+
+	// The root is only known at the end. Every CIDv1 sha2-256 root has the same
+	// length, so write a placeholder header and patch it afterwards.
+	placeholder := cid.Sum(cid.DagPB, nil)
+	w, err := NewWriter(tmp, placeholder)
+	if err != nil {
+		return cid.CID{}, err
+	}
+	b := &builder{w: w, chunkSize: opts.ChunkSize}
+	root, err := b.add(src)
+	if err != nil {
+		return cid.CID{}, err
+	}
+
+	hdr := encodeHeader([]cid.CID{root.CID})
+	if len(hdr) != len(encodeHeader([]cid.CID{placeholder})) {
+		return cid.CID{}, fmt.Errorf("car: unexpected root cid length for %s", root.CID)
+	}
+	if _, err := tmp.WriteAt(append(binary.AppendUvarint(nil, uint64(len(hdr))), hdr...), prefix); err != nil {
+		return cid.CID{}, err
+	}
+	if opts.Version == 2 {
+		v2 := make([]byte, 0, len(v2Pragma)+v2HeaderSize)
+		v2 = append(v2, v2Pragma...)
+		v2 = append(v2, make([]byte, 16)...) // characteristics
+		v2 = binary.LittleEndian.AppendUint64(v2, uint64(prefix))
+		v2 = binary.LittleEndian.AppendUint64(v2, uint64(w.Size()))
+		v2 = binary.LittleEndian.AppendUint64(v2, 0) // no index
+		if _, err := tmp.WriteAt(v2, 0); err != nil {
+			return cid.CID{}, err
+		}
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return cid.CID{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return cid.CID{}, err
+	}
+	return root.CID, os.Rename(tmp.Name(), dst)
+}
+
+type builder struct {
+	w         *Writer
+	chunkSize int
+}
+
+// add writes the DAG for path and returns a link to its root.
+func (b *builder) add(path string) (unixfs.Link, error) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return unixfs.Link{}, err
+	}
+	switch {
+	case fi.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return unixfs.Link{}, err
+		}
+		return b.put(unixfs.SymlinkNode(target), 0)
+	case fi.IsDir():
+		return b.addDir(path)
+	case fi.Mode().IsRegular():
+		return b.addFile(path)
+	}
+	return unixfs.Link{}, fmt.Errorf("car: unsupported file type %s: %s", fi.Mode().Type(), path)
+}
+
+func (b *builder) addFile(path string) (unixfs.Link, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return unixfs.Link{}, err
+	}
+	defer f.Close()
+
+	cb := cid.NewBuilder(cid.Options{ChunkSize: b.chunkSize, OnBlock: b.w.Put})
+	if _, err := io.Copy(cb, f); err != nil {
+		return unixfs.Link{}, err
+	}
+	n, err := cb.Sum()
+	if err != nil {
+		return unixfs.Link{}, err
+	}
+	return unixfs.Link{CID: n.CID, Tsize: n.Tsize}, nil
+}
+
+func (b *builder) addDir(path string) (unixfs.Link, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return unixfs.Link{}, err
+	}
+	links := make([]unixfs.Link, 0, len(entries))
+	var childTsize uint64
+	for _, e := range entries {
+		l, err := b.add(filepath.Join(path, e.Name()))
+		if err != nil {
+			return unixfs.Link{}, err
+		}
+		l.Name = e.Name()
+		links = append(links, l)
+		childTsize += l.Tsize
+	}
+	return b.put(unixfs.DirectoryNode(links), childTsize)
+}
+
+func (b *builder) put(blk unixfs.Block, childTsize uint64) (unixfs.Link, error) {
+	if err := b.w.Put(blk); err != nil {
+		return unixfs.Link{}, err
+	}
+	return unixfs.Link{CID: blk.CID, Tsize: uint64(len(blk.Data)) + childTsize}, nil
+}
@@ -0,0 +1,241 @@
+package car
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/unixfs"
+)
+
+// maxSection bounds a single header or block section.
+const maxSection = 32 << 20
+
+// Reader iterates the blocks of a CARv1 or CARv2 stream.
+type Reader struct {
+	br     *bufio.Reader
+	header Header
+}
+
+// NewReader reads the header from r. For CARv2 the inner CARv1 payload is
+// read; any trailing index is ignored.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	raw, err := readSection(br)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+	h, err := decodeHeader(raw)
+	if err != nil {
+		return nil, err
+	}
+	if h.Version == 1 {
+		return &Reader{br: br, header: h}, nil
+	}
+	if h.Version != 2 {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidHeader, h.Version)
+	}
+
+	var v2 [v2HeaderSize]byte
+	if _, err := io.ReadFull(br, v2[:]); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+	dataOffset := binary.LittleEndian.Uint64(v2[16:])
+	dataSize := binary.LittleEndian.Uint64(v2[24:])
+	consumed := uint64(len(v2Pragma) + v2HeaderSize)
+	if dataOffset < consumed {
+		return nil, fmt.Errorf("%w: data offset %d", ErrInvalidHeader, dataOffset)
+	}
+	if _, err := io.CopyN(io.Discard, br, int64(dataOffset-consumed)); err != nil {
+		return nil, err
+	}
+	inner, err := NewReader(io.LimitReader(br, int64(dataSize)))
+	if err != nil {
+		return nil, err
+	}
+	if inner.header.Version != 1 {
+		return nil, fmt.Errorf("%w: nested version %d", ErrInvalidHeader, inner.header.Version)
+	}
+	inner.header.Version = 2
+	return inner, nil
+}
+
+func (r *Reader) Header() Header { return r.header }
+
+// Next returns the next block, or io.EOF after the last one. Blocks are not
+// verified; see Validate or cid.CID.Verify.
+func (r *Reader) Next() (cid.Block, error) {
+	sec, err := readSection(r.br)
+	if err != nil {
+		return cid.Block{}, err
+	}
+	c, n, err := unixfs.DecodePrefix(sec)
+	if err != nil {
+		return cid.Block{}, fmt.Errorf("car: %w", err)
+	}
+	return cid.Block{CID: c, Data: sec[n:]}, nil
+}
+
+// Validate reads the whole CAR, checking every block against its CID and
+// that each root is present.
+func Validate(r io.Reader) (Header, error) {
+	cr, err := NewReader(r)
+	if err != nil {
+		return Header{}, err
+	}
+	seen := map[string]bool{}
+	for {
+		b, err := cr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return cr.header, err
+		}
+		if err := b.CID.Verify(b.Data); err != nil {
+			return cr.header, fmt.Errorf("%w: %s: %v", ErrBlockMismatch, b.CID, err)
+		}
+		seen[string(b.CID.Bytes())] = true
+	}
+	for _, root := range cr.header.Roots {
+		if !seen[string(root.Bytes())] {
+			return cr.header, fmt.Errorf("%w: %s", ErrMissingRoot, root)
+		}
+	}
+	return cr.header, nil
+}
+
+func readSection(br *bufio.Reader) ([]byte, error) {
+	l, err := binary.ReadUvarint(br)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	if l == 0 || l > maxSection {
+		return nil, fmt.Errorf("car: invalid section length %d", l)
+	}
+	buf := make([]byte, l)
+	if _, err := io.ReadFull(br, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf, nil
+}
+
+// decodeHeader parses the DAG-CBOR header map.
+func decodeHeader(b []byte) (Header, error) {
+	d := cborDecoder{b: b}
+	major, n, err := d.head()
+	if err != nil || major != 5 {
+		return Header{}, fmt.Errorf("%w: not a map", ErrInvalidHeader)
+	}
+	var h Header
+	for i := uint64(0); i < n; i++ {
+		key, err := d.text()
+		if err != nil {
+			return Header{}, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+		}
+		switch key {
+		case "version":
+			major, v, err := d.head()
+			if err != nil || major != 0 {
+				return Header{}, fmt.Errorf("%w: bad version", ErrInvalidHeader)
+			}
+			h.Version = int(v)
+		case "roots":
+			major, count, err := d.head()
+			if err != nil || major != 4 {
+				return Header{}, fmt.Errorf("%w: bad roots", ErrInvalidHeader)
+			}
+			for j := uint64(0); j < count; j++ {
+				c, err := d.link()
+				if err != nil {
+					return Header{}, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+				}
+				h.Roots = append(h.Roots, c)
+			}
+		default:
+			return Header{}, fmt.Errorf("%w: unexpected key %q", ErrInvalidHeader, key)
+		}
+	}
+	if h.Version == 0 {
+		return Header{}, fmt.Errorf("%w: missing version", ErrInvalidHeader)
+	}
+	return h, nil
+}
+
+// cborDecoder understands just enough DAG-CBOR for CAR headers.
+type cborDecoder struct {
+	b []byte
+}
+
+func (d *cborDecoder) head() (major byte, n uint64, err error) {
+	if len(d.b) == 0 {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	major, info := d.b[0]>>5, d.b[0]&0x1f
+	d.b = d.b[1:]
+	var size int
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, errors.New("unsupported cbor length encoding")
+	}
+	if len(d.b) < size {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	for _, c := range d.b[:size] {
+		n = n<<8 | uint64(c)
+	}
+	d.b = d.b[size:]
+	return major, n, nil
+}
+
+func (d *cborDecoder) bytes(wantMajor byte) ([]byte, error) {
+	major, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	if major != wantMajor || uint64(len(d.b)) < n {
+		return nil, errors.New("unexpected cbor item")
+	}
+	out := d.b[:n]
+	d.b = d.b[n:]
+	return out, nil
+}
+
+func (d *cborDecoder) text() (string, error) {
+	b, err := d.bytes(3)
+	return string(b), err
+}
+
+func (d *cborDecoder) link() (cid.CID, error) {
+	major, tag, err := d.head()
+	if err != nil || major != 6 || tag != 42 {
+		return cid.CID{}, errors.New("expected cid link")
+	}
+	b, err := d.bytes(2)
+	if err != nil {
+		return cid.CID{}, err
+	}
+	if len(b) == 0 || b[0] != 0 {
+		return cid.CID{}, errors.New("invalid cid link prefix")
+	}
+	return unixfs.Decode(b[1:])
+}
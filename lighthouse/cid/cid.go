@@ -0,0 +1,204 @@
+// Package cid computes content identifiers locally, chunking content the
+// same way the Lighthouse upload node does for /api/v0/add?cid-version=1:
+// fixed-size 256 KiB chunks, raw leaves, a balanced dag-pb tree of at most
+// 174 links per node, sha2-256 multihashes and base32 CIDv1 strings.
+package cid
+
+import (
+	"io"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/unixfs"
+)
+
+// CID is a parsed content identifier.
+type CID = unixfs.CID
+
+// Block is an encoded block together with its CID.
+type Block = unixfs.Block
+
+// Multicodec codes for the blocks produced by Builder.
+const (
+	Raw    = unixfs.CodecRaw
+	DagPB  = unixfs.CodecDagPB
+	SHA256 = unixfs.HashSHA2256
+)
+
+const (
+	DefaultChunkSize = 256 << 10
+	DefaultMaxLinks  = unixfs.DefaultLinksPerBlock
+)
+
+// Parse decodes a CIDv0 or a base32/base58 CIDv1 string.
+func Parse(s string) (CID, error) { return unixfs.Parse(s) }
+
+// Sum returns the CIDv1 of a single block.
+func Sum(codec uint64, data []byte) CID { return unixfs.Sum(codec, data) }
+
+type Options struct {
+	ChunkSize int // default DefaultChunkSize
+	MaxLinks  int // default DefaultMaxLinks
+	// DagPBLeaves wraps each chunk in a dag-pb UnixFS node instead of a raw
+	// block, as kubo does with --raw-leaves=false.
+	DagPBLeaves bool
+	// OnBlock, if set, receives every block of the DAG, leaves first and
+	// each parent after its children. The root is the last block.
+	OnBlock func(Block) error
+}
+
+// Compute returns the CID r would get when uploaded with default settings.
+func Compute(r io.Reader) (CID, error) {
+	return ComputeWithOptions(r, Options{})
+}
+
+func ComputeWithOptions(r io.Reader, opts Options) (CID, error) {
+	b := NewBuilder(opts)
+	if _, err := io.Copy(b, r); err != nil {
+		return CID{}, err
+	}
+	root, err := b.Sum()
+	return root.CID, err
+}
+
+// Node describes the root of a built file DAG.
+type Node struct {
+	CID      CID
+	FileSize uint64 // content bytes
+	Tsize    uint64 // cumulative encoded size of the DAG
+}
+
+// Builder is an io.Writer that builds a file DAG incrementally, keeping at
+// most one chunk and one partially filled node per tree level in memory.
+type Builder struct {
+	opts   Options
+	buf    []byte
+	levels [][]unixfs.Child
+	leaves int
+	err    error
+}
+
+func NewBuilder(opts Options) *Builder {
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = DefaultChunkSize
+	}
+	if opts.MaxLinks < 2 {
+		opts.MaxLinks = DefaultMaxLinks
+	}
+	return &Builder{opts: opts, buf: make([]byte, 0, opts.ChunkSize)}
+}
+
+func (b *Builder) Write(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	n := len(p)
+	for len(p) > 0 {
+		k := min(b.opts.ChunkSize-len(b.buf), len(p))
+		b.buf = append(b.buf, p[:k]...)
+		p = p[k:]
+		if len(b.buf) == b.opts.ChunkSize {
+			if err := b.flushLeaf(); err != nil {
+				return n - len(p), err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Reset discards everything written so far.
+func (b *Builder) Reset() {
+	b.buf = b.buf[:0]
+	b.levels = nil
+	b.leaves = 0
+	b.err = nil
+}
+
+func (b *Builder) flushLeaf() error {
+	var blk unixfs.Block
+	if b.opts.DagPBLeaves {
+		d := unixfs.Data{Type: unixfs.TypeFile, Data: b.buf, FileSize: uint64(len(b.buf))}
+		enc := unixfs.Node{Data: d.Encode()}.Encode()
+		blk = unixfs.Block{CID: unixfs.Sum(unixfs.CodecDagPB, enc), Data: enc}
+	} else {
+		blk = unixfs.Block{CID: unixfs.Sum(unixfs.CodecRaw, b.buf), Data: append([]byte(nil), b.buf...)}
+	}
+	if err := b.emit(blk); err != nil {
+		return err
+	}
+	b.leaves++
+	leaf := unixfs.Child{CID: blk.CID, FileSize: uint64(len(b.buf)), Tsize: uint64(len(blk.Data))}
+	b.buf = b.buf[:0]
+	return b.push(0, leaf)
+}
+
+// push appends c to the given level, collapsing full levels upwards.
+func (b *Builder) push(level int, c unixfs.Child) error {
+	for len(b.levels) <= level {
+		b.levels = append(b.levels, nil)
+	}
+	b.levels[level] = append(b.levels[level], c)
+	if len(b.levels[level]) < b.opts.MaxLinks {
+		return nil
+	}
+	parent, err := b.link(b.levels[level])
+	if err != nil {
+		return err
+	}
+	b.levels[level] = nil
+	return b.push(level+1, parent)
+}
+
+func (b *Builder) link(children []unixfs.Child) (unixfs.Child, error) {
+	blk := unixfs.FileNode(children)
+	if err := b.emit(blk); err != nil {
+		return unixfs.Child{}, err
+	}
+	c := unixfs.Child{CID: blk.CID, Tsize: uint64(len(blk.Data))}
+	for _, ch := range children {
+		c.FileSize += ch.FileSize
+		c.Tsize += ch.Tsize
+	}
+	return c, nil
+}
+
+func (b *Builder) emit(blk unixfs.Block) error {
+	if b.opts.OnBlock == nil {
+		return nil
+	}
+	if err := b.opts.OnBlock(blk); err != nil {
+		b.err = err
+		return err
+	}
+	return nil
+}
+
+// Sum flushes the final chunk and returns the root. The Builder must not be
+// written to afterwards (call Reset to reuse it).
+func (b *Builder) Sum() (Node, error) {
+	if b.err != nil {
+		return Node{}, b.err
+	}
+	if len(b.buf) > 0 || b.leaves == 0 {
+		if err := b.flushLeaf(); err != nil {
+			return Node{}, err
+		}
+	}
+	for i := 0; ; i++ {
+		top := i == len(b.levels)-1
+		if top && len(b.levels[i]) == 1 {
+			c := b.levels[i][0]
+			return Node{CID: c.CID, FileSize: c.FileSize, Tsize: c.Tsize}, nil
+		}
+		if len(b.levels[i]) == 0 {
+			continue
+		}
+		parent, err := b.link(b.levels[i])
+		if err != nil {
+			return Node{}, err
+		}
+		b.levels[i] = nil
+		if top {
+			b.levels = append(b.levels, nil)
+		}
+		b.levels[i+1] = append(b.levels[i+1], parent)
+	}
+}
@@ -0,0 +1,118 @@
+package cid
+
+import (
+	"bytes"
+	"testing"
+)
+
+// gen returns n deterministic pseudo-random bytes, so the goldens below do
+// not depend on compressible input.
+func gen(n int) []byte {
+	b := make([]byte, n)
+	x := uint32(1)
+	for i := range b {
+		x = x*1664525 + 1013904223
+		b[i] = byte(x >> 24)
+	}
+	return b
+}
+
+// Golden CIDs from kubo `ipfs add --cid-version=1 --chunker=size-262144`
+// with --raw-leaves=true and --raw-leaves=false.
+var goldens = []struct {
+	name       string
+	size       int
+	raw, dagpb string
+}{
+	{"empty", 0,
+		"bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku",
+		"bafybeif7ztnhq65lumvvtr4ekcwd2ifwgm3awq4zfr3srh462rwyinlb4y"},
+	{"small", 11,
+		"bafkreihlsw2qlos3etk3wf4jycvqvqewm6kng3bcr2yw4sg4g2mzr6hxry",
+		"bafybeiax47shlwywslwr3yopgsxb7vzvurqogv5wmwf5t6fkizkhvgx2qy"},
+	{"one chunk", DefaultChunkSize,
+		"bafkreidd6k2rtu2k6woh25xxx2nxk2d2ifjrn54h6wjqfbqijyvdl2nrmq",
+		"bafybeietvmdwbylyf57n2z5its676ut75b225rarbegameiq2xqe23a4ry"},
+	{"174 leaves", DefaultMaxLinks * DefaultChunkSize,
+		"bafybeid3u6ywprs7daea5cngbzwx3dxtb5ov464joy4p4ctguxa4dtm4ue",
+		"bafybeihspr2j4y2elawsnha32ztlpus6j4evu3tgk5uoi3tenl7o4pjuky"},
+	{"175 leaves", DefaultMaxLinks*DefaultChunkSize + 1,
+		"bafybeiak6ul52u63lryr7aaih6sa7n6wbxwdhwgwngisfkspjlt2orzvk4",
+		"bafybeigw2xa4ng4iqjk4cnxwdo7qo7htkji2rhhizgm3wbq4uhu4ebwvtm"},
+}
+
+func TestComputeGolden(t *testing.T) {
+	for _, g := range goldens {
+		data := gen(g.size)
+		for _, tc := range []struct {
+			mode string
+			opts Options
+			want string
+		}{
+			{"raw leaves", Options{}, g.raw},
+			{"dag-pb leaves", Options{DagPBLeaves: true}, g.dagpb},
+		} {
+			t.Run(g.name+"/"+tc.mode, func(t *testing.T) {
+				got, err := ComputeWithOptions(bytes.NewReader(data), tc.opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.String() != tc.want {
+					t.Errorf("got %s, want %s", got, tc.want)
+				}
+			})
+		}
+	}
+}
+
+// Splitting writes across chunk boundaries must not change the DAG.
+func TestBuilderWriteSizes(t *testing.T) {
+	data := gen(3*DefaultChunkSize + 7)
+	want, err := Compute(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range []int{1000, DefaultChunkSize, DefaultChunkSize + 1} {
+		b := NewBuilder(Options{})
+		for p := data; len(p) > 0; {
+			k := min(step, len(p))
+			if _, err := b.Write(p[:k]); err != nil {
+				t.Fatal(err)
+			}
+			p = p[k:]
+		}
+		root, err := b.Sum()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !root.CID.Equal(want) {
+			t.Errorf("step %d: got %s, want %s", step, root.CID, want)
+		}
+		if root.FileSize != uint64(len(data)) {
+			t.Errorf("step %d: FileSize = %d, want %d", step, root.FileSize, len(data))
+		}
+	}
+}
+
+// OnBlock must see every block exactly once, ending with the root.
+func TestBuilderOnBlock(t *testing.T) {
+	var blocks []Block
+	b := NewBuilder(Options{OnBlock: func(blk Block) error {
+		if !Sum(blk.CID.Codec, blk.Data).Equal(blk.CID) {
+			t.Errorf("block %s does not hash to its CID", blk.CID)
+		}
+		blocks = append(blocks, blk)
+		return nil
+	}})
+	b.Write(gen(2*DefaultChunkSize + 1))
+	root, err := b.Sum()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 4 {
+		t.Fatalf("got %d blocks, want 3 leaves and a root", len(blocks))
+	}
+	if !blocks[3].CID.Equal(root.CID) {
+		t.Errorf("last block %s is not the root %s", blocks[3].CID, root.CID)
+	}
+}
@@ -1,28 +1,46 @@
 package lighthouse
 
 import (
+	"log/slog"
 	"net/http"
+	"net/url"
 
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/auth"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/credentials"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/deals"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/files"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/gateway"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/cfg"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/httpx"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/ipns"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/storage"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/user"
 )
 
 type Client struct {
-	http *http.Client
-	cfg  Config
+	http  *http.Client
+	cfg   Config
+	retry RetryPolicy
+	rate  rateLimits
+
+	creds      CredentialsProvider
+	middleware []Middleware
+	tracer     telemetry.Tracer
+	meter      telemetry.Meter
+	logger     *slog.Logger
 
 	storage StorageService
 	files   FilesService
 	deals   DealsService
 	ipns    IPNSService
+	gateway GatewayService
+	user    UserService
+	auth    AuthService
 }
 
 func NewClient(h *http.Client, options ...Option) *Client {
-	c := &Client{cfg: DefaultConfig()}
+	c := &Client{cfg: DefaultConfig(), retry: DefaultRetryPolicy()}
 
 	for _, opt := range options {
 		opt(c)
@@ -35,9 +53,19 @@ func NewClient(h *http.Client, options ...Option) *Client {
 		}
 	}
 
+	if c.creds == nil {
+		c.creds = credentials.Chain(credentials.Static(c.cfg.APIKey), credentials.Env(""))
+	}
+
 	hx := httpx.New(c.http, httpx.Options{
-		UserAgent: c.cfg.UserAgent,
-		APIKey:    c.cfg.APIKey,
+		UserAgent:   c.cfg.UserAgent,
+		Credentials: c.creds,
+		Retry:       c.retry,
+		Limiters:    c.rate.limiters(c.cfg.Hosts),
+		Middleware:  c.middleware,
+		Tracer:      c.tracer,
+		Meter:       c.meter,
+		Logger:      c.logger,
 	})
 	cc := cfg.Config(c.cfg)
 
@@ -45,6 +73,9 @@ func NewClient(h *http.Client, options ...Option) *Client {
 	c.files = files.New(hx, cc)
 	c.deals = deals.New(hx, cc)
 	c.ipns = ipns.New(hx, cc)
+	c.gateway = gateway.New(hx, cc)
+	c.user = user.New(hx, cc)
+	c.auth = auth.New(hx, cc)
 
 	return c
 }
@@ -53,3 +84,31 @@ func (c *Client) Storage() StorageService { return c.storage }
 func (c *Client) Files() FilesService     { return c.files }
 func (c *Client) Deals() DealsService     { return c.deals }
 func (c *Client) IPNS() IPNSService       { return c.ipns }
+func (c *Client) Gateway() GatewayService { return c.gateway }
+func (c *Client) User() UserService       { return c.user }
+func (c *Client) Auth() AuthService       { return c.auth }
+
+type rateLimits struct {
+	api, upload, gateway RateLimit
+}
+
+// limiters builds one bucket per configured host. Hosts that resolve to the
+// same authority share a bucket.
+func (r rateLimits) limiters(h Hosts) map[string]*httpx.Limiter {
+	m := map[string]*httpx.Limiter{}
+	for _, e := range []struct {
+		base  string
+		limit RateLimit
+	}{{h.API, r.api}, {h.Upload, r.upload}, {h.Gateway, r.gateway}} {
+		u, err := url.Parse(e.base)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		if l := httpx.NewLimiter(e.limit); l != nil {
+			if _, dup := m[u.Host]; !dup {
+				m[u.Host] = l
+			}
+		}
+	}
+	return m
+}
@@ -0,0 +1,27 @@
+package lighthouse
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// ErrTooManyItems is returned by Collect when a sequence exceeds its limit.
+var ErrTooManyItems = errors.New("lighthouse: too many items")
+
+// Collect gathers a sequence such as Files().All into a slice, stopping at
+// the first error. If max > 0 and the sequence yields more than max items,
+// Collect stops early and returns the first max items with ErrTooManyItems.
+func Collect[T any](seq iter.Seq2[T, error], max int) ([]T, error) {
+	var out []T
+	for v, err := range seq {
+		if err != nil {
+			return out, err
+		}
+		if max > 0 && len(out) == max {
+			return out, fmt.Errorf("%w: more than %d", ErrTooManyItems, max)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
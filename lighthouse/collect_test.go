@@ -0,0 +1,54 @@
+package lighthouse_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+func TestCollect(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t, lighthousetest.WithPageSize(2))
+	files := srv.Client().Files()
+	for i := range 5 {
+		if _, err := srv.Client().Storage().UploadText(ctx, fmt.Sprintf("f%d.txt", i), "x"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Hitting the limit stops the listing: two pages hold the first four
+	// items, and the fourth shows the limit was passed.
+	got, err := lighthouse.Collect(files.All(ctx), 3)
+	if len(got) != 3 || !errors.Is(err, lighthouse.ErrTooManyItems) {
+		t.Fatalf("Collect(max 3) = %d items, %v", len(got), err)
+	}
+	if hits := srv.Hits(http.MethodGet, "/api/user/files_uploaded"); hits != 2 {
+		t.Errorf("files_uploaded hits = %d, want 2", hits)
+	}
+
+	for _, tc := range []struct {
+		max, n int
+		err    error
+	}{
+		{0, 5, nil},
+		{5, 5, nil},
+		{4, 4, lighthouse.ErrTooManyItems},
+		{1, 1, lighthouse.ErrTooManyItems},
+	} {
+		got, err := lighthouse.Collect(files.All(ctx, schema.WithPrefetch(1)), tc.max)
+		if len(got) != tc.n || !errors.Is(err, tc.err) {
+			t.Errorf("Collect(max %d) = %d items, %v; want %d, %v", tc.max, len(got), err, tc.n, tc.err)
+		}
+	}
+
+	srv.Fail(http.MethodGet, "/api/user/files_uploaded", http.StatusForbidden, -1)
+	if got, err := lighthouse.Collect(files.All(ctx), 0); err == nil || len(got) != 0 {
+		t.Errorf("failed listing: Collect = %d items, %v", len(got), err)
+	}
+}
@@ -0,0 +1,197 @@
+// Package credentials supplies the API key for each request, so keys can be
+// rotated while a lighthouse.Client is in use.
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// EnvVar is the environment variable read by Env when no name is given.
+const EnvVar = "LIGHTHOUSE_API_KEY"
+
+// ErrNoCredentials is returned by a Provider that has no key to offer.
+// Chain moves on to the next provider, and the client sends such requests
+// without an Authorization header.
+var ErrNoCredentials = errors.New("credentials: no API key available")
+
+// Provider returns the API key to use for a request. It is called once per
+// request attempt and must be safe for concurrent use.
+type Provider interface {
+	APIKey(ctx context.Context) (string, error)
+}
+
+// ProviderFunc adapts a function to Provider.
+type ProviderFunc func(ctx context.Context) (string, error)
+
+func (f ProviderFunc) APIKey(ctx context.Context) (string, error) { return f(ctx) }
+
+// Static always returns key. An empty key means no credentials.
+func Static(key string) Provider {
+	return ProviderFunc(func(context.Context) (string, error) {
+		if key == "" {
+			return "", ErrNoCredentials
+		}
+		return key, nil
+	})
+}
+
+// Env reads the environment variable name (EnvVar if empty) on every call.
+func Env(name string) Provider {
+	if name == "" {
+		name = EnvVar
+	}
+	return ProviderFunc(func(context.Context) (string, error) {
+		if v := os.Getenv(name); v != "" {
+			return v, nil
+		}
+		return "", ErrNoCredentials
+	})
+}
+
+// Chain returns the key from the first provider that has one.
+func Chain(providers ...Provider) Provider {
+	return ProviderFunc(func(ctx context.Context) (string, error) {
+		for _, p := range providers {
+			key, err := p.APIKey(ctx)
+			if errors.Is(err, ErrNoCredentials) {
+				continue
+			}
+			return key, err
+		}
+		return "", ErrNoCredentials
+	})
+}
+
+// File reads the key from a file, trimming surrounding whitespace, and
+// re-reads it whenever the file's size or modification time changes. A
+// missing file means no credentials.
+func File(path string) Provider {
+	return &fileProvider{path: path}
+}
+
+type fileProvider struct {
+	path string
+
+	mu      sync.Mutex
+	key     string
+	size    int64
+	modTime time.Time
+}
+
+func (f *fileProvider) APIKey(context.Context) (string, error) {
+	st, err := os.Stat(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNoCredentials
+	}
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.key != "" && st.Size() == f.size && st.ModTime().Equal(f.modTime) {
+		return f.key, nil
+	}
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", err
+	}
+	key := string(bytes.TrimSpace(b))
+	if key == "" {
+		return "", fmt.Errorf("credentials: %s is empty", f.path)
+	}
+	f.key, f.size, f.modTime = key, st.Size(), st.ModTime()
+	return key, nil
+}
+
+// Rotating serves a key that can be replaced at any time with Set, and
+// optionally refreshes itself from a fetch function (a secrets manager, a
+// vault lease) once the current key is older than the refresh interval.
+type Rotating struct {
+	fetch func(ctx context.Context) (string, error)
+	every time.Duration
+
+	mu       sync.Mutex
+	key      string
+	fetched  time.Time
+	err      error         // from the last failed fetch
+	gen      uint64        // bumped by Set so a slower fetch cannot undo it
+	inflight chan struct{} // closed when the running fetch returns
+}
+
+// NewRotating returns a Rotating provider. fetch may be nil, in which case
+// keys only change through Set.
+func NewRotating(initial string, fetch func(ctx context.Context) (string, error), every time.Duration) *Rotating {
+	return &Rotating{fetch: fetch, every: every, key: initial, fetched: time.Now()}
+}
+
+// Set replaces the current key; subsequent requests use it immediately.
+func (r *Rotating) Set(key string) {
+	r.mu.Lock()
+	r.key, r.fetched, r.err = key, time.Now(), nil
+	r.gen++
+	r.mu.Unlock()
+}
+
+// APIKey returns the current key, refreshing it first when it is due. Only
+// one refresh runs at a time, without holding the lock: other callers keep
+// getting the previous key meanwhile, or wait for the refresh if there is
+// none. If a refresh fails the previous key keeps being served until the
+// next attempt; the error is returned only when there is no key at all.
+func (r *Rotating) APIKey(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	if r.fetch != nil && (r.key == "" || time.Since(r.fetched) >= r.every) {
+		switch {
+		case r.inflight == nil:
+			r.refresh(ctx)
+		case r.key == "":
+			wait := r.inflight
+			r.mu.Unlock()
+			select {
+			case <-wait:
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+			r.mu.Lock()
+		}
+	}
+	defer r.mu.Unlock()
+	if r.key == "" {
+		if r.err != nil {
+			return "", r.err
+		}
+		return "", ErrNoCredentials
+	}
+	return r.key, nil
+}
+
+// refresh calls fetch with r.mu released and stores the result. r.mu must
+// be held; it is held again on return.
+func (r *Rotating) refresh(ctx context.Context) {
+	done := make(chan struct{})
+	r.inflight = done
+	gen := r.gen
+	r.mu.Unlock()
+
+	key, err := r.fetch(ctx)
+
+	r.mu.Lock()
+	r.inflight = nil
+	close(done)
+	switch {
+	case r.gen != gen:
+		// Set ran meanwhile; its key is newer.
+	case err == nil && key != "":
+		r.key, r.fetched, r.err = key, time.Now(), nil
+	case err == nil:
+		r.err = ErrNoCredentials
+	default:
+		r.err = err
+	}
+}
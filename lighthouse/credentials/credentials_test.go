@@ -0,0 +1,205 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChainSkipsMissingCredentials(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("LIGHTHOUSE_TEST_UNSET", "")
+
+	key, err := Chain(
+		Static(""),
+		Env("LIGHTHOUSE_TEST_UNSET"),
+		File(filepath.Join(t.TempDir(), "missing")),
+		Static("from-static"),
+		Static("unused"),
+	).APIKey(ctx)
+	if err != nil || key != "from-static" {
+		t.Errorf("APIKey() = %q, %v; want from-static", key, err)
+	}
+
+	if _, err := Chain(Static(""), Env("LIGHTHOUSE_TEST_UNSET")).APIKey(ctx); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("all empty: err = %v, want ErrNoCredentials", err)
+	}
+
+	boom := errors.New("vault sealed")
+	failing := ProviderFunc(func(context.Context) (string, error) { return "", boom })
+	if _, err := Chain(failing, Static("fallback")).APIKey(ctx); !errors.Is(err, boom) {
+		t.Errorf("failing provider: err = %v, want it to stop the chain", err)
+	}
+}
+
+func TestFileReloadsOnChange(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "key")
+	p := File(path)
+
+	if _, err := p.APIKey(ctx); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("missing file: err = %v, want ErrNoCredentials", err)
+	}
+
+	write := func(key string, mtime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(key), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(want string) {
+		t.Helper()
+		if got, err := p.APIKey(ctx); err != nil || got != want {
+			t.Errorf("APIKey() = %q, %v; want %q", got, err, want)
+		}
+	}
+
+	base := time.Now().Add(-time.Hour)
+	write("  key-one\n", base)
+	check("key-one")
+
+	// Same size, later mtime.
+	write("  key-two\n", base.Add(time.Minute))
+	check("key-two")
+
+	// Same mtime, different size.
+	write("key-three", base.Add(time.Minute))
+	check("key-three")
+
+	write("\n", base.Add(2*time.Minute))
+	if _, err := p.APIKey(ctx); err == nil {
+		t.Error("empty file: want an error")
+	}
+}
+
+func TestRotatingRefresh(t *testing.T) {
+	ctx := context.Background()
+	var calls atomic.Int32
+	r := NewRotating("old", func(context.Context) (string, error) {
+		calls.Add(1)
+		return "new", nil
+	}, time.Hour)
+
+	if key, _ := r.APIKey(ctx); key != "old" || calls.Load() != 0 {
+		t.Errorf("fresh key: got %q after %d fetches", key, calls.Load())
+	}
+	r.mu.Lock()
+	r.fetched = time.Now().Add(-2 * time.Hour)
+	r.mu.Unlock()
+	if key, _ := r.APIKey(ctx); key != "new" || calls.Load() != 1 {
+		t.Errorf("stale key: got %q after %d fetches", key, calls.Load())
+	}
+	r.Set("manual")
+	if key, _ := r.APIKey(ctx); key != "manual" {
+		t.Errorf("after Set: got %q", key)
+	}
+}
+
+// While a refresh is running, other callers get the old key at once and
+// no second fetch starts.
+func TestRotatingServesOldKeyDuringFetch(t *testing.T) {
+	ctx := context.Background()
+	started, release := make(chan struct{}), make(chan struct{})
+	var calls atomic.Int32
+	r := NewRotating("old", func(context.Context) (string, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return "new", nil
+	}, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	done := make(chan string)
+	go func() {
+		key, _ := r.APIKey(ctx)
+		done <- key
+	}()
+	<-started
+
+	for range 10 {
+		if key, err := r.APIKey(ctx); err != nil || key != "old" {
+			t.Fatalf("during refresh: APIKey() = %q, %v; want old", key, err)
+		}
+	}
+	close(release)
+	if key := <-done; key != "new" {
+		t.Errorf("refreshing caller got %q, want new", key)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fetch ran %d times, want 1", n)
+	}
+}
+
+// Without a key, concurrent callers share a single fetch.
+func TestRotatingWaitsWithoutKey(t *testing.T) {
+	ctx := context.Background()
+	var calls atomic.Int32
+	r := NewRotating("", func(context.Context) (string, error) {
+		calls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return "first", nil
+	}, time.Hour)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if key, err := r.APIKey(ctx); err != nil || key != "first" {
+				t.Errorf("APIKey() = %q, %v; want first", key, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("fetch ran %d times, want 1", n)
+	}
+}
+
+// A Set made while a fetch is running must not be overwritten by it.
+func TestRotatingSetDuringFetch(t *testing.T) {
+	ctx := context.Background()
+	started, release := make(chan struct{}), make(chan struct{})
+	r := NewRotating("", func(context.Context) (string, error) {
+		close(started)
+		<-release
+		return "fetched", nil
+	}, time.Hour)
+
+	done := make(chan struct{})
+	go func() {
+		r.APIKey(ctx)
+		close(done)
+	}()
+	<-started
+	r.Set("manual")
+	close(release)
+	<-done
+	if key, _ := r.APIKey(ctx); key != "manual" {
+		t.Errorf("APIKey() = %q, want manual", key)
+	}
+}
+
+func TestRotatingFetchError(t *testing.T) {
+	ctx := context.Background()
+	boom := errors.New("vault sealed")
+	r := NewRotating("", func(context.Context) (string, error) { return "", boom }, time.Hour)
+	if _, err := r.APIKey(ctx); !errors.Is(err, boom) {
+		t.Errorf("err = %v, want %v", err, boom)
+	}
+
+	r = NewRotating("old", func(context.Context) (string, error) { return "", boom }, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if key, err := r.APIKey(ctx); err != nil || key != "old" {
+		t.Errorf("failed refresh: APIKey() = %q, %v; want old key", key, err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/cfg"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/httpx"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
 )
 
 type Service struct {
@@ -18,11 +19,14 @@ func New(h *httpx.Client, c cfg.Config) *Service {
 	return &Service{h: h, cfg: c}
 }
 
-func (s *Service) Status(ctx context.Context, cid string) ([]schema.DealStatus, error) {
+func (s *Service) Status(ctx context.Context, cid string) (_ []schema.DealStatus, err error) {
+	ctx, op := s.h.StartOp(ctx, "deals.Status", telemetry.String(telemetry.AttrCID, cid))
+	defer func() { op.End(err) }()
+
 	u := s.cfg.Hosts.API + "/api/lighthouse/deal_status?cid=" + url.QueryEscape(cid)
 
 	var deals []schema.DealStatus
-	_, err := s.h.WriteJSON(ctx, "GET", u, nil, &deals)
+	_, err = s.h.WriteJSON(ctx, "GET", u, nil, &deals)
 	if err != nil {
 		return nil, err
 	}
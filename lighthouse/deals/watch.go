@@ -0,0 +1,122 @@
+package deals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/httpx"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+// ErrWatchTimeout is returned by Watch when the replicas are not active
+// before the timeout.
+var ErrWatchTimeout = errors.New("deals: watch timed out")
+
+// Watch polls the deal status of cid until the requested number of replicas
+// is active, yielding an update each time a deal changes phase. The first
+// update reflects the initial state. Polling backs off while nothing changes
+// and resets on every change. Transient API errors are retried; the
+// iteration ends with an error on authorization failures, cancellation or
+// ErrWatchTimeout.
+func (s *Service) Watch(ctx context.Context, cid string, opts ...schema.WatchOption) iter.Seq2[schema.DealUpdate, error] {
+	o := schema.DefaultWatchOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	o.Replicas = max(o.Replicas, 1)
+	if o.PollInterval <= 0 {
+		o.PollInterval = schema.DefaultWatchOptions().PollInterval
+	}
+	o.MaxInterval = max(o.MaxInterval, o.PollInterval)
+
+	return func(yield func(schema.DealUpdate, error) bool) {
+		if o.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+			defer cancel()
+		}
+
+		var (
+			last     string
+			interval = o.PollInterval
+			seen     bool
+			latest   schema.DealUpdate
+		)
+		for {
+			ds, err := s.Status(ctx, cid)
+			switch {
+			case err == nil:
+				u := update(cid, ds, o.Replicas)
+				if fp := fingerprint(ds); !seen || fp != last {
+					seen, last, latest, interval = true, fp, u, o.PollInterval
+					if !yield(u, nil) || u.ActiveReplicas >= o.Replicas {
+						return
+					}
+				} else {
+					interval = min(interval*3/2, o.MaxInterval)
+				}
+			case ctx.Err() != nil:
+			case errors.Is(err, httpx.ErrUnauthorized), errors.Is(err, httpx.ErrQuotaExceeded):
+				yield(schema.DealUpdate{}, err)
+				return
+			default:
+				s.h.Logger().DebugContext(ctx, "lighthouse deal watch poll failed",
+					slog.String("cid", cid),
+					slog.String("error", err.Error()))
+				interval = min(interval*3/2, o.MaxInterval)
+			}
+
+			t := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				err := ctx.Err()
+				if errors.Is(err, context.DeadlineExceeded) && o.Timeout > 0 {
+					err = fmt.Errorf("%w: %d of %d replicas active after %s",
+						ErrWatchTimeout, latest.ActiveReplicas, o.Replicas, o.Timeout)
+				}
+				yield(schema.DealUpdate{}, err)
+				return
+			case <-t.C:
+			}
+		}
+	}
+}
+
+func update(cid string, ds []schema.DealStatus, replicas int) schema.DealUpdate {
+	phases := make([]schema.DealPhase, len(ds))
+	active := 0
+	for i, d := range ds {
+		phases[i] = d.Phase()
+		if phases[i] == schema.DealActive {
+			active++
+		}
+	}
+	sort.Slice(phases, func(i, j int) bool { return phases[i] > phases[j] })
+	phase := schema.DealQueued
+	if len(phases) >= replicas {
+		phase = phases[replicas-1]
+	}
+	return schema.DealUpdate{CID: cid, Phase: phase, ActiveReplicas: active, Deals: ds, Time: time.Now()}
+}
+
+// fingerprint identifies the set of deals and their phases.
+func fingerprint(ds []schema.DealStatus) string {
+	keys := make([]string, len(ds))
+	for i, d := range ds {
+		id := d.DealUUID
+		if id == "" {
+			id = d.StorageProvider + "/" + d.PieceCID + "/" + strconv.FormatInt(d.ChainDealID, 10)
+		}
+		keys[i] = id + "=" + d.Phase().String()
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
@@ -0,0 +1,38 @@
+package deals_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+// A sub-second interval passed by the caller must be used as given.
+func TestWatchHonoursPollInterval(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	client := srv.Client()
+	res, err := client.Storage().UploadText(ctx, "a.txt", "watch me")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	var last schema.DealUpdate
+	for u, err := range client.Deals().Watch(ctx, res.Hash,
+		schema.WithPollInterval(time.Millisecond, 5*time.Millisecond),
+		schema.WithWatchTimeout(time.Second)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		last = u
+	}
+	if last.ActiveReplicas != 1 {
+		t.Errorf("ActiveReplicas = %d, want 1", last.ActiveReplicas)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("watch took %s; poll interval was not honoured", d)
+	}
+}
@@ -0,0 +1,252 @@
+// Package encryption implements the SDK's client-side streaming encryption
+// format, used by uploads made with schema.UploadOptions.EncryptKey.
+//
+// A stream is a 32-byte header followed by AES-256-GCM sealed chunks:
+//
+//	magic "LHE" | version (1) | chunk size (uint32 BE) | salt (24 bytes)
+//
+// Each file gets its own key, HMAC-SHA256(EncryptKey, "lighthouse-go-sdk/v1"
+// || salt). Chunk i is sealed with nonce = uint88(i) || lastFlag and the
+// header as additional data, so reordering, truncation and header tampering
+// are all detected. This is independent of Lighthouse's server-side
+// (Kavach) encryption.
+package encryption
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	KeySize          = 32
+	Version          = 1
+	DefaultChunkSize = 64 << 10
+	HeaderSize       = 3 + 1 + 4 + saltSize
+	Overhead         = 16 // GCM tag per chunk
+
+	saltSize     = 24
+	maxChunkSize = 16 << 20
+	kdfLabel     = "lighthouse-go-sdk/v1"
+)
+
+var magic = [3]byte{'L', 'H', 'E'}
+
+var (
+	ErrKeySize       = fmt.Errorf("encryption: key must be %d bytes", KeySize)
+	ErrHeader        = errors.New("encryption: invalid header")
+	ErrAuthFailed    = errors.New("encryption: message authentication failed")
+	ErrTruncated     = errors.New("encryption: stream truncated")
+	ErrTrailingBytes = errors.New("encryption: data after final chunk")
+)
+
+// EncryptedSize returns the ciphertext length for n plaintext bytes.
+func EncryptedSize(n int64) int64 {
+	chunks := (n + DefaultChunkSize - 1) / DefaultChunkSize
+	if chunks == 0 {
+		chunks = 1
+	}
+	return HeaderSize + n + chunks*Overhead
+}
+
+// NewEncryptReader returns a reader producing the encrypted form of r.
+func NewEncryptReader(r io.Reader, key []byte) (io.Reader, error) {
+	if len(key) != KeySize {
+		return nil, ErrKeySize
+	}
+	hdr := make([]byte, HeaderSize)
+	copy(hdr, magic[:])
+	hdr[3] = Version
+	binary.BigEndian.PutUint32(hdr[4:], DefaultChunkSize)
+	if _, err := rand.Read(hdr[8:]); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key, hdr[8:])
+	if err != nil {
+		return nil, err
+	}
+	return &encryptReader{
+		src:   bufio.NewReaderSize(r, DefaultChunkSize+1),
+		aead:  aead,
+		hdr:   hdr,
+		out:   hdr,
+		plain: make([]byte, DefaultChunkSize),
+	}, nil
+}
+
+type encryptReader struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	hdr     []byte
+	out     []byte // pending ciphertext
+	plain   []byte
+	counter uint64
+	done    bool
+	err     error
+}
+
+func (e *encryptReader) Read(p []byte) (int, error) {
+	for len(e.out) == 0 {
+		if e.err != nil {
+			return 0, e.err
+		}
+		if e.done {
+			return 0, io.EOF
+		}
+		e.sealNext()
+	}
+	n := copy(p, e.out)
+	e.out = e.out[n:]
+	return n, nil
+}
+
+func (e *encryptReader) sealNext() {
+	n, err := io.ReadFull(e.src, e.plain)
+	last := false
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		last = true
+	case err != nil:
+		e.err = err
+		return
+	default:
+		// Full chunk: it is the last one only if nothing follows.
+		if _, err := e.src.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			e.err = err
+			return
+		}
+	}
+	e.out = e.aead.Seal(e.out[:0:0], nonce(e.counter, last), e.plain[:n], e.hdr)
+	e.counter++
+	e.done = last
+}
+
+// NewDecryptReader returns a reader yielding the plaintext of an encrypted
+// stream. Every chunk is authenticated before any of its bytes are returned.
+func NewDecryptReader(r io.Reader, key []byte) (io.Reader, error) {
+	if len(key) != KeySize {
+		return nil, ErrKeySize
+	}
+	hdr := make([]byte, HeaderSize)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrHeader, err)
+	}
+	if [3]byte(hdr[:3]) != magic || hdr[3] != Version {
+		return nil, ErrHeader
+	}
+	chunk := binary.BigEndian.Uint32(hdr[4:])
+	if chunk == 0 || chunk > maxChunkSize {
+		return nil, ErrHeader
+	}
+	aead, err := newAEAD(key, hdr[8:])
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		src:   bufio.NewReaderSize(r, int(chunk)+Overhead+1),
+		aead:  aead,
+		hdr:   hdr,
+		buf:   make([]byte, int(chunk)+Overhead),
+		plain: make([]byte, 0, int(chunk)),
+	}, nil
+}
+
+type decryptReader struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	hdr     []byte
+	buf     []byte
+	plain   []byte
+	out     []byte
+	counter uint64
+	done    bool
+	err     error
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.done {
+			return 0, io.EOF
+		}
+		d.openNext()
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+func (d *decryptReader) openNext() {
+	n, err := io.ReadFull(d.src, d.buf)
+	last := false
+	switch {
+	case err == io.EOF:
+		d.err = ErrTruncated
+		return
+	case err == io.ErrUnexpectedEOF:
+		last = true
+	case err != nil:
+		d.err = err
+		return
+	default:
+		if _, err := d.src.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			d.err = err
+			return
+		}
+	}
+	if n < Overhead {
+		d.err = ErrTruncated
+		return
+	}
+	plain, err := d.aead.Open(d.plain[:0], nonce(d.counter, last), d.buf[:n], d.hdr)
+	if err != nil {
+		// Distinguish a stream cut at a chunk boundary (or extended past its
+		// final chunk) from corrupted data.
+		_, flipped := d.aead.Open(nil, nonce(d.counter, !last), d.buf[:n], d.hdr)
+		switch {
+		case flipped == nil && last:
+			d.err = ErrTruncated
+		case flipped == nil:
+			d.err = ErrTrailingBytes
+		default:
+			d.err = ErrAuthFailed
+		}
+		return
+	}
+	d.out = plain
+	d.counter++
+	d.done = last
+}
+
+func newAEAD(key, salt []byte) (cipher.AEAD, error) {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(kdfLabel))
+	mac.Write(salt)
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func nonce(counter uint64, last bool) []byte {
+	n := make([]byte, 12)
+	binary.BigEndian.PutUint64(n[3:11], counter)
+	if last {
+		n[11] = 1
+	}
+	return n
+}
@@ -0,0 +1,134 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+var testKey = bytes.Repeat([]byte{0x42}, KeySize)
+
+func encrypt(t *testing.T, plain []byte) []byte {
+	t.Helper()
+	r, err := NewEncryptReader(bytes.NewReader(plain), testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func decrypt(ciphertext []byte) ([]byte, error) {
+	r, err := NewDecryptReader(bytes.NewReader(ciphertext), testKey)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func plaintext(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i * 7)
+	}
+	return b
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 1, DefaultChunkSize - 1, DefaultChunkSize, DefaultChunkSize + 1, 3 * DefaultChunkSize} {
+		plain := plaintext(n)
+		ct := encrypt(t, plain)
+		if int64(len(ct)) != EncryptedSize(int64(n)) {
+			t.Errorf("%d bytes: ciphertext is %d bytes, EncryptedSize says %d", n, len(ct), EncryptedSize(int64(n)))
+		}
+		got, err := decrypt(ct)
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Errorf("%d bytes: round trip mismatch", n)
+		}
+	}
+}
+
+// Each stream gets a fresh salt, so equal plaintexts never share ciphertext.
+func TestSaltIsRandom(t *testing.T) {
+	if bytes.Equal(encrypt(t, []byte("same")), encrypt(t, []byte("same"))) {
+		t.Error("two encryptions of the same plaintext are identical")
+	}
+}
+
+func TestTruncation(t *testing.T) {
+	ct := encrypt(t, plaintext(2*DefaultChunkSize+10))
+	chunk := DefaultChunkSize + Overhead
+	for _, tc := range []struct {
+		name string
+		n    int
+		want error
+	}{
+		{"header only", HeaderSize, ErrTruncated},
+		{"at chunk boundary", HeaderSize + chunk, ErrTruncated},
+		{"before final chunk", HeaderSize + 2*chunk, ErrTruncated},
+		{"mid chunk", HeaderSize + chunk + 100, ErrAuthFailed},
+		{"inside header", HeaderSize - 1, ErrHeader},
+	} {
+		if _, err := decrypt(ct[:tc.n]); !errors.Is(err, tc.want) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+// Bytes after a full final chunk are reported as such; after a short one
+// they change that chunk's ciphertext and fail authentication instead.
+func TestTrailingBytes(t *testing.T) {
+	ct := encrypt(t, plaintext(DefaultChunkSize))
+	if _, err := decrypt(append(ct, 0)); !errors.Is(err, ErrTrailingBytes) {
+		t.Errorf("err = %v, want ErrTrailingBytes", err)
+	}
+}
+
+func TestTamper(t *testing.T) {
+	ct := encrypt(t, plaintext(2*DefaultChunkSize))
+	for _, tc := range []struct {
+		name string
+		off  int
+	}{
+		{"salt", 10},
+		{"first chunk", HeaderSize + 5},
+		{"tag", HeaderSize + DefaultChunkSize + 3},
+		{"last chunk", len(ct) - 1},
+	} {
+		bad := bytes.Clone(ct)
+		bad[tc.off] ^= 1
+		if _, err := decrypt(bad); !errors.Is(err, ErrAuthFailed) {
+			t.Errorf("%s: err = %v, want ErrAuthFailed", tc.name, err)
+		}
+	}
+
+	// Swapping two full chunks breaks their counters.
+	chunk := DefaultChunkSize + Overhead
+	swapped := bytes.Clone(ct)
+	copy(swapped[HeaderSize:], ct[HeaderSize+chunk:HeaderSize+2*chunk])
+	copy(swapped[HeaderSize+chunk:], ct[HeaderSize:HeaderSize+chunk])
+	if _, err := decrypt(swapped); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("reordered chunks: err = %v, want ErrAuthFailed", err)
+	}
+}
+
+func TestWrongKey(t *testing.T) {
+	ct := encrypt(t, []byte("secret"))
+	r, err := NewDecryptReader(bytes.NewReader(ct), bytes.Repeat([]byte{1}, KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(r); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("err = %v, want ErrAuthFailed", err)
+	}
+	if _, err := NewEncryptReader(nil, testKey[:16]); err != ErrKeySize {
+		t.Errorf("short key: err = %v, want ErrKeySize", err)
+	}
+}
@@ -2,25 +2,50 @@ package lighthouse
 
 import (
 	"errors"
-	"fmt"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/deals"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/gateway"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/httpx"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/storage"
 )
 
-type Error struct {
-	Status  int    `json:"status"`
-	Code    string `json:"code"`
-	Message string `json:"message"`
-	Body    []byte `json:"-"`
-}
+// Error is returned for every non-2xx response from Lighthouse.
+type Error = httpx.Error
 
-func (e *Error) Error() string {
-	if e == nil {
-		return "<nil>"
-	}
-	return fmt.Sprintf("lighthouse: status=%d code=%s msg=%s", e.Status, e.Code, e.Message)
-}
+// Error categories, usable with errors.Is on any error returned by a service.
+var (
+	ErrUnauthorized  = httpx.ErrUnauthorized
+	ErrQuotaExceeded = httpx.ErrQuotaExceeded
+	ErrNotFound      = httpx.ErrNotFound
+	ErrRateLimited   = httpx.ErrRateLimited
+	ErrServerError   = httpx.ErrServerError
+)
 
 func AsError(err error) (*Error, bool) {
 	var le *Error
 	ok := errors.As(err, &le)
 	return le, ok
 }
+
+// ErrCIDMismatch is returned when an upload made with schema.WithVerifyCID
+// comes back with a different CID than the one computed locally.
+var ErrCIDMismatch = storage.ErrCIDMismatch
+
+// ErrResumeUnsupported is returned by ResumeUpload when the upload node does
+// not accept block/put.
+var ErrResumeUnsupported = storage.ErrResumeUnsupported
+
+// ErrRangeNotSupported is returned by ranged gateway reads when the gateway
+// answers with the whole object instead of the requested range.
+var ErrRangeNotSupported = gateway.ErrRangeNotSupported
+
+// ErrIntegrity matches, via errors.Is, the *IntegrityError returned when a
+// verified gateway fetch receives data that does not match the CID.
+var ErrIntegrity = gateway.ErrIntegrity
+
+// IntegrityError identifies the block that failed verification.
+type IntegrityError = gateway.IntegrityError
+
+// ErrWatchTimeout is returned by Deals().Watch when the requested replicas
+// are not active in time.
+var ErrWatchTimeout = deals.ErrWatchTimeout
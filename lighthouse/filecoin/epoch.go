@@ -0,0 +1,50 @@
+// Package filecoin converts chain epochs to wall-clock time.
+package filecoin
+
+import "time"
+
+// EpochDuration is the block time on every Filecoin network.
+const EpochDuration = 30 * time.Second
+
+// Network identifies a chain by its genesis time.
+type Network struct {
+	Name    string
+	Genesis time.Time
+}
+
+var (
+	Mainnet  = Network{Name: "mainnet", Genesis: time.Unix(1598306400, 0).UTC()}
+	Calibnet = Network{Name: "calibnet", Genesis: time.Unix(1667326380, 0).UTC()}
+)
+
+// NetworkByName returns Mainnet or Calibnet.
+func NetworkByName(name string) (Network, bool) {
+	switch name {
+	case "mainnet", "":
+		return Mainnet, true
+	case "calibnet", "calibration":
+		return Calibnet, true
+	}
+	return Network{}, false
+}
+
+// EpochTime returns when epoch e starts.
+func (n Network) EpochTime(e int64) time.Time {
+	return n.Genesis.Add(time.Duration(e) * EpochDuration)
+}
+
+// EpochAt returns the epoch in progress at t.
+func (n Network) EpochAt(t time.Time) int64 {
+	d := t.Sub(n.Genesis)
+	e := int64(d / EpochDuration)
+	if d < 0 && d%EpochDuration != 0 {
+		e--
+	}
+	return e
+}
+
+// EpochTime returns when mainnet epoch e starts.
+func EpochTime(e int64) time.Time { return Mainnet.EpochTime(e) }
+
+// EpochAt returns the mainnet epoch in progress at t.
+func EpochAt(t time.Time) int64 { return Mainnet.EpochAt(t) }
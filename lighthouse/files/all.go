@@ -0,0 +1,92 @@
+package files
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+// All iterates over every uploaded file, following LastKey cursors until the
+// listing is exhausted. Breaking out of the loop stops further requests. An
+// error is yielded once and ends the iteration. With schema.WithPrefetch the
+// next pages are requested while the current one is being consumed.
+func (s *Service) All(ctx context.Context, opts ...schema.ListOption) iter.Seq2[schema.FileEntry, error] {
+	o := schema.DefaultListOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return func(yield func(schema.FileEntry, error) bool) {
+		each := func(page *schema.FileList, err error) bool {
+			if err != nil {
+				yield(schema.FileEntry{}, err)
+				return false
+			}
+			for _, f := range page.Data {
+				if !yield(f, nil) {
+					return false
+				}
+			}
+			return true
+		}
+		if o.Prefetch <= 0 {
+			s.pages(ctx, each)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		type result struct {
+			page *schema.FileList
+			err  error
+		}
+		ch := make(chan result, o.Prefetch)
+		go func() {
+			defer close(ch)
+			s.pages(ctx, func(page *schema.FileList, err error) bool {
+				select {
+				case ch <- result{page, err}:
+					return true
+				case <-ctx.Done():
+					return false
+				}
+			})
+		}()
+		defer func() {
+			cancel()
+			for range ch {
+			}
+		}()
+		for r := range ch {
+			if !each(r.page, r.err) {
+				return
+			}
+		}
+	}
+}
+
+// pages calls fn for each page of the listing until fn returns false, a
+// request fails or the cursor runs out.
+func (s *Service) pages(ctx context.Context, fn func(*schema.FileList, error) bool) {
+	var cursor *string
+	seen := map[string]bool{}
+	for {
+		page, err := s.List(ctx, cursor)
+		if err != nil {
+			fn(nil, err)
+			return
+		}
+		if !fn(page, nil) {
+			return
+		}
+		if page.LastKey == nil || *page.LastKey == "" || len(page.Data) == 0 {
+			return
+		}
+		if seen[*page.LastKey] {
+			fn(nil, fmt.Errorf("files: pagination cursor %q repeated", *page.LastKey))
+			return
+		}
+		seen[*page.LastKey] = true
+		cursor = page.LastKey
+	}
+}
@@ -0,0 +1,114 @@
+package files_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+const listPath = "/api/user/files_uploaded"
+
+// upload stores n files on a server with two files per page and returns
+// their names newest first, the order the listing uses.
+func upload(t *testing.T, n int) (*lighthousetest.Server, []string) {
+	t.Helper()
+	srv := lighthousetest.NewServer(t, lighthousetest.WithPageSize(2))
+	var names []string
+	for i := range n {
+		name := fmt.Sprintf("f%d.txt", i)
+		if _, err := srv.Client().Storage().UploadText(context.Background(), name, name); err != nil {
+			t.Fatal(err)
+		}
+		names = append([]string{name}, names...)
+	}
+	return srv, names
+}
+
+// allRunning reports whether any goroutine started by All is still alive.
+func allRunning() bool {
+	buf := make([]byte, 1<<20)
+	return strings.Contains(string(buf[:runtime.Stack(buf, true)]), "files.(*Service).All")
+}
+
+func TestAllPrefetch(t *testing.T) {
+	srv, want := upload(t, 7)
+	for _, n := range []int{0, 1, 3} {
+		var got []string
+		for f, err := range srv.Client().Files().All(context.Background(), schema.WithPrefetch(n)) {
+			if err != nil {
+				t.Fatalf("prefetch %d: %v", n, err)
+			}
+			got = append(got, f.Name)
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("prefetch %d: All = %q, want %q", n, got, want)
+		}
+	}
+}
+
+// counting counts the requests a client starts.
+type counting struct{ n atomic.Int32 }
+
+func (c *counting) RoundTrip(r *http.Request) (*http.Response, error) {
+	c.n.Add(1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// Breaking out of the loop stops the prefetching goroutine before All
+// returns and starts no further requests.
+func TestAllPrefetchBreak(t *testing.T) {
+	srv, _ := upload(t, 20)
+	rt := &counting{}
+	client := srv.Client(lighthouse.WithHTTPClient(&http.Client{Transport: rt}))
+	for f, err := range client.Files().All(context.Background(), schema.WithPrefetch(2)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Name != "" {
+			break
+		}
+	}
+	if allRunning() {
+		t.Error("prefetch goroutine still running after break")
+	}
+	// One page consumed, at most two buffered and one in flight.
+	n := rt.n.Load()
+	if n > 4 {
+		t.Errorf("%d requests after reading one page", n)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if later := rt.n.Load(); later != n {
+		t.Errorf("requests grew from %d to %d after break", n, later)
+	}
+}
+
+func TestAllError(t *testing.T) {
+	for _, n := range []int{0, 2} {
+		srv, _ := upload(t, 3)
+		srv.Fail(http.MethodGet, listPath, http.StatusForbidden, -1)
+		var errs int
+		for _, err := range srv.Client().Files().All(context.Background(), schema.WithPrefetch(n)) {
+			var le *lighthouse.Error
+			if !errors.As(err, &le) || le.Status != http.StatusForbidden {
+				t.Errorf("prefetch %d: err = %v, want a 403", n, err)
+			}
+			errs++
+		}
+		if errs != 1 {
+			t.Errorf("prefetch %d: yielded %d errors, want one", n, errs)
+		}
+		if allRunning() {
+			t.Errorf("prefetch %d: goroutine still running after the error", n)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/cfg"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/httpx"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
 )
 
 type Service struct {
@@ -18,7 +19,10 @@ func New(h *httpx.Client, c cfg.Config) *Service {
 	return &Service{h: h, cfg: c}
 }
 
-func (s *Service) List(ctx context.Context, lastKey *string) (*schema.FileList, error) {
+func (s *Service) List(ctx context.Context, lastKey *string) (_ *schema.FileList, err error) {
+	ctx, op := s.h.StartOp(ctx, "files.List")
+	defer func() { op.End(err) }()
+
 	u := s.cfg.Hosts.API + "/api/user/files_uploaded"
 	if lastKey != nil {
 		u += "?lastKey=" + url.QueryEscape(*lastKey)
@@ -30,7 +34,7 @@ func (s *Service) List(ctx context.Context, lastKey *string) (*schema.FileList,
 		LastKey    *string            `json:"lastKey,omitempty"`
 	}
 
-	_, err := s.h.WriteJSON(ctx, "GET", u, nil, &dataResult)
+	_, err = s.h.WriteJSON(ctx, "GET", u, nil, &dataResult)
 	if err != nil {
 		return nil, err
 	}
@@ -42,28 +46,37 @@ func (s *Service) List(ctx context.Context, lastKey *string) (*schema.FileList,
 	}, nil
 }
 
-func (s *Service) Info(ctx context.Context, cid string) (*schema.FileInfo, error) {
+func (s *Service) Info(ctx context.Context, cid string) (_ *schema.FileInfo, err error) {
+	ctx, op := s.h.StartOp(ctx, "files.Info", telemetry.String(telemetry.AttrCID, cid))
+	defer func() { op.End(err) }()
+
 	u := s.cfg.Hosts.API + "/api/lighthouse/file_info?cid=" + url.QueryEscape(cid)
 	var out schema.FileInfo
-	_, err := s.h.WriteJSON(ctx, "GET", u, nil, &out)
+	_, err = s.h.WriteJSON(ctx, "GET", u, nil, &out)
 	return &out, err
 }
 
-func (s *Service) Pin(ctx context.Context, cid, name string) error {
+func (s *Service) Pin(ctx context.Context, cid, name string) (err error) {
+	ctx, op := s.h.StartOp(ctx, "files.Pin", telemetry.String(telemetry.AttrCID, cid))
+	defer func() { op.End(err) }()
+
 	u := s.cfg.Hosts.API + "/api/lighthouse/pin"
 	body := map[string]string{"cid": cid, "fileName": name}
-	_, err := s.h.WriteJSON(ctx, "POST", u, body, nil)
+	_, err = s.h.WriteJSON(ctx, "POST", u, body, nil)
 	return err
 }
 
-func (s *Service) Delete(ctx context.Context, id string) error {
+func (s *Service) Delete(ctx context.Context, id string) (err error) {
+	ctx, op := s.h.StartOp(ctx, "files.Delete")
+	defer func() { op.End(err) }()
+
 	u := s.cfg.Hosts.API + "/api/user/delete_file?id=" + url.QueryEscape(id)
 
 	var response struct {
 		Message string `json:"message"`
 	}
 
-	_, err := s.h.WriteJSON(ctx, "DELETE", u, nil, &response)
+	_, err = s.h.WriteJSON(ctx, "DELETE", u, nil, &response)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,281 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/encryption"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/httpx"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
+)
+
+// Download writes content to dstPath atomically. Bytes are streamed into
+// dstPath+".part", which is renamed into place once complete. If the
+// transfer breaks it is resumed with a range request from the bytes already
+// written, both within the call (per the client's RetryPolicy) and across
+// calls for the same /ipfs/ path. The path and ETag behind a partial file are
+// kept in dstPath+".part.json"; a partial left for other content, or by an
+// /ipns/ name whose content can change, is discarded, and a resume sends
+// If-Range so a changed object restarts from zero.
+//
+// With schema.WithVerifiedDownload the content is fetched as a CAR and
+// checked block by block (see GetVerified); such downloads restart from zero.
+// With schema.WithDecryptKey the partial file holds ciphertext and is
+// decrypted once the download completes.
+func (s *Service) Download(ctx context.Context, cid, dstPath string, opts ...schema.DownloadOption) (_ *schema.ContentInfo, err error) {
+	ctx, op := s.h.StartOp(ctx, "gateway.Download", telemetry.String(telemetry.AttrCID, cid))
+	defer func() { op.End(err) }()
+
+	o := schema.DefaultDownloadOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.DecryptKey != nil && len(o.DecryptKey) != encryption.KeySize {
+		return nil, encryption.ErrKeySize
+	}
+	p, err := ContentPath(cid, "")
+	if err != nil {
+		return nil, err
+	}
+
+	part, meta := dstPath+".part", dstPath+".part.json"
+	f, err := os.OpenFile(part, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+	var info *schema.ContentInfo
+	if o.Verify {
+		if _, err := restart(f); err != nil {
+			return nil, err
+		}
+		os.Remove(meta)
+		info, err = s.verifiedTo(ctx, p, f, o.OnProgress)
+	} else {
+		var offset int64
+		if offset, err = f.Seek(0, io.SeekEnd); err != nil {
+			return nil, err
+		}
+		pm := readPartMeta(meta)
+		if offset > 0 && (pm.Path != p || !strings.HasPrefix(p, "/ipfs/")) {
+			if offset, err = restart(f); err != nil {
+				return nil, err
+			}
+			pm = partMeta{}
+		}
+		info, err = s.fetchTo(ctx, p, f, offset, pm.ETag, meta, o.OnProgress)
+	}
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(meta)
+	if err := f.Sync(); err != nil {
+		return nil, err
+	}
+
+	if o.DecryptKey != nil {
+		if err := decryptTo(f, dstPath, o.DecryptKey); err != nil {
+			return nil, err
+		}
+		f.Close()
+		f = nil
+		os.Remove(part)
+		info.Size, info.TotalSize = -1, -1
+		return info, nil
+	}
+
+	err = f.Close()
+	f = nil
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Rename(part, dstPath); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// partMeta identifies the content a partial download file holds.
+type partMeta struct {
+	Path string `json:"path"`
+	ETag string `json:"etag,omitempty"`
+}
+
+// readPartMeta returns the metadata saved in file, or the zero value if it
+// is missing or unreadable.
+func readPartMeta(file string) partMeta {
+	var m partMeta
+	if b, err := os.ReadFile(file); err == nil {
+		json.Unmarshal(b, &m)
+	}
+	return m
+}
+
+// fetchTo appends p to f starting at offset, reissuing a range request from
+// the current position whenever the body breaks off mid-stream. etag is the
+// ETag of the bytes already in f; the path and ETag are saved to meta before
+// any new bytes are written.
+func (s *Service) fetchTo(ctx context.Context, p string, f *os.File, offset int64, etag, meta string, onProg schema.ProgressCallback) (*schema.ContentInfo, error) {
+	policy := s.h.RetryPolicy()
+	var (
+		total int64 = -1
+		saved *partMeta
+	)
+	for attempt := 1; ; attempt++ {
+		res, info, err := s.fetchRange(ctx, p, offset, -1, etag)
+		var he *httpx.Error
+		if offset > 0 && errors.As(err, &he) && he.Status == http.StatusRequestedRangeNotSatisfiable {
+			// The partial file is at least as long as the object; start over
+			// rather than trust it.
+			if offset, err = restart(f); err != nil {
+				return nil, err
+			}
+			res, info, err = s.fetchRange(ctx, p, 0, -1, "")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if offset > 0 && res.StatusCode != http.StatusPartialContent {
+			if offset, err = restart(f); err != nil {
+				res.Body.Close()
+				return nil, err
+			}
+		}
+		if offset == 0 {
+			etag = info.ETag
+		}
+		if m := (partMeta{Path: p, ETag: etag}); saved == nil || *saved != m {
+			b, _ := json.Marshal(m)
+			if err := os.WriteFile(meta, b, 0o644); err != nil {
+				res.Body.Close()
+				return nil, err
+			}
+			saved = &m
+		}
+		if info.TotalSize >= 0 {
+			total = info.TotalSize
+		}
+
+		var src io.Reader = res.Body
+		if onProg != nil {
+			src = &progressReader{r: res.Body, done: offset, total: total, onProg: onProg}
+		}
+		n, err := io.Copy(f, src)
+		res.Body.Close()
+		offset += n
+		if err == nil && total >= 0 && offset != total {
+			err = io.ErrUnexpectedEOF
+		}
+		if err == nil {
+			info.Offset, info.Size, info.TotalSize = 0, offset, offset
+			return info, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !policy.Retryable(nil, err) || attempt >= policy.MaxAttempts {
+			return nil, fmt.Errorf("download interrupted at %d bytes: %w", offset, err)
+		}
+
+		wait := policy.Backoff(attempt)
+		s.h.Logger().DebugContext(ctx, "lighthouse resuming download",
+			slog.Int64("offset", offset),
+			slog.Int("attempt", attempt),
+			slog.Duration("wait", wait),
+			slog.String("error", err.Error()))
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// verifiedTo writes the verified content of p to f.
+func (s *Service) verifiedTo(ctx context.Context, p string, f *os.File, onProg schema.ProgressCallback) (*schema.ContentInfo, error) {
+	vr, info, err := s.getVerified(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	defer vr.Close()
+	var src io.Reader = vr
+	if onProg != nil {
+		src = &progressReader{r: vr, total: info.Size, onProg: onProg}
+	}
+	if _, err := io.Copy(f, src); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// restart truncates f so the download begins again from zero.
+func restart(f *os.File) (int64, error) {
+	if err := f.Truncate(0); err != nil {
+		return 0, err
+	}
+	return f.Seek(0, io.SeekStart)
+}
+
+// decryptTo decrypts the ciphertext in f into dstPath via a temporary file in
+// the same directory.
+func decryptTo(f *os.File, dstPath string, key []byte) (err error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	dr, err := encryption.NewDecryptReader(f, key)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dstPath), "."+filepath.Base(dstPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+	if _, err := io.Copy(tmp, dr); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dstPath)
+}
+
+type progressReader struct {
+	r      io.Reader
+	done   int64
+	total  int64
+	onProg schema.ProgressCallback
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.done += int64(n)
+		p.onProg(schema.Progress{Uploaded: p.done, Total: p.total})
+	}
+	return n, err
+}
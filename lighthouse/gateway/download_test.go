@@ -0,0 +1,221 @@
+package gateway_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+)
+
+var errCut = errors.New("connection cut")
+
+// cutTransport records gateway requests and breaks the next response body
+// after limit bytes with err.
+type cutTransport struct {
+	mu    sync.Mutex
+	limit int64 // -1 leaves bodies intact
+	err   error
+	reqs  []http.Header
+}
+
+func (c *cutTransport) cut(limit int64, err error) {
+	c.mu.Lock()
+	c.limit, c.err = limit, err
+	c.mu.Unlock()
+}
+
+func (c *cutTransport) requests() []http.Header {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]http.Header(nil), c.reqs...)
+}
+
+func (c *cutTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	res, err := http.DefaultTransport.RoundTrip(r)
+	if err != nil || !strings.HasPrefix(r.URL.Path, "/ipfs/") {
+		return res, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reqs = append(c.reqs, r.Header.Clone())
+	if c.limit >= 0 {
+		res.Body = &cutBody{r: io.LimitReader(res.Body, c.limit), c: res.Body, err: c.err}
+		c.limit = -1
+	}
+	return res, nil
+}
+
+type cutBody struct {
+	r   io.Reader
+	c   io.Closer
+	err error
+}
+
+func (b *cutBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err == io.EOF {
+		err = b.err
+	}
+	return n, err
+}
+
+func (b *cutBody) Close() error { return b.c.Close() }
+
+// uploadText stores text on srv and returns its CID.
+func uploadText(t *testing.T, srv *lighthousetest.Server, name, text string) string {
+	t.Helper()
+	res, err := srv.Client().Storage().UploadText(context.Background(), name, text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.Hash
+}
+
+func checkFile(t *testing.T, path, want string) {
+	t.Helper()
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("%s holds %d bytes starting %.20q, want %d bytes starting %.20q", path, len(got), got, len(want), want)
+	}
+	for _, leftover := range []string{path + ".part", path + ".part.json"} {
+		if _, err := os.Stat(leftover); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s left behind", leftover)
+		}
+	}
+}
+
+func TestDownloadResumesWithinCall(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	text := strings.Repeat("0123456789", 300)
+	c := uploadText(t, srv, "a.txt", text)
+	rt := &cutTransport{}
+	rt.cut(1000, io.ErrUnexpectedEOF)
+	client := srv.Client(lighthouse.WithHTTPClient(&http.Client{Transport: rt}))
+
+	dst := filepath.Join(t.TempDir(), "out")
+	if _, err := client.Gateway().Download(ctx, c, dst); err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, dst, text)
+
+	reqs := rt.requests()
+	if len(reqs) != 2 {
+		t.Fatalf("gateway requests = %d, want 2", len(reqs))
+	}
+	if r := reqs[1].Get("Range"); r != "bytes=1000-" {
+		t.Errorf("resume Range = %q, want bytes=1000-", r)
+	}
+	if ir := reqs[1].Get("If-Range"); ir != `"`+c+`"` {
+		t.Errorf("resume If-Range = %q, want the first response's ETag", ir)
+	}
+}
+
+func TestDownloadResumesAcrossCalls(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	text := strings.Repeat("abcdefghij", 300)
+	c := uploadText(t, srv, "a.txt", text)
+	rt := &cutTransport{}
+	rt.cut(1200, errCut)
+	client := srv.Client(lighthouse.WithHTTPClient(&http.Client{Transport: rt}))
+	dst := filepath.Join(t.TempDir(), "out")
+
+	if _, err := client.Gateway().Download(ctx, c, dst); !errors.Is(err, errCut) {
+		t.Fatalf("first Download: err = %v, want it interrupted", err)
+	}
+	if fi, err := os.Stat(dst + ".part"); err != nil || fi.Size() != 1200 {
+		t.Fatalf("partial file after interruption: %v, %v", fi, err)
+	}
+
+	if _, err := client.Gateway().Download(ctx, c, dst); err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, dst, text)
+	reqs := rt.requests()
+	last := reqs[len(reqs)-1]
+	if r, ir := last.Get("Range"), last.Get("If-Range"); r != "bytes=1200-" || ir != `"`+c+`"` {
+		t.Errorf("resume sent Range %q, If-Range %q", r, ir)
+	}
+}
+
+// A partial file left by other content is discarded, not prepended.
+func TestDownloadDiscardsForeignPartial(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	a := uploadText(t, srv, "a.txt", strings.Repeat("A", 2000))
+	text := strings.Repeat("B", 2000)
+	b := uploadText(t, srv, "b.txt", text)
+	client := srv.Client()
+	dir := t.TempDir()
+
+	// No metadata at all, as left by an older SDK.
+	dst := filepath.Join(dir, "bare")
+	os.WriteFile(dst+".part", bytes.Repeat([]byte("A"), 500), 0o644)
+	if _, err := client.Gateway().Download(ctx, b, dst); err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, dst, text)
+
+	// Interrupted download of a, then a download of b to the same path.
+	rt := &cutTransport{}
+	rt.cut(500, errCut)
+	cutClient := srv.Client(lighthouse.WithHTTPClient(&http.Client{Transport: rt}))
+	dst = filepath.Join(dir, "other")
+	if _, err := cutClient.Gateway().Download(ctx, a, dst); !errors.Is(err, errCut) {
+		t.Fatalf("Download(a): err = %v, want it interrupted", err)
+	}
+	if _, err := cutClient.Gateway().Download(ctx, b, dst); err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, dst, text)
+	if reqs := rt.requests(); reqs[len(reqs)-1].Get("Range") != "" {
+		t.Errorf("download of b sent Range %q", reqs[len(reqs)-1].Get("Range"))
+	}
+}
+
+// A stale ETag makes the gateway ignore the range, and the partial file is
+// replaced rather than appended to.
+func TestDownloadRestartsOnETagMismatch(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	text := strings.Repeat("C", 2000)
+	c := uploadText(t, srv, "c.txt", text)
+	dst := filepath.Join(t.TempDir(), "out")
+	os.WriteFile(dst+".part", bytes.Repeat([]byte("x"), 500), 0o644)
+	os.WriteFile(dst+".part.json", []byte(`{"path":"/ipfs/`+c+`","etag":"\"stale\""}`), 0o644)
+
+	rt := &cutTransport{limit: -1}
+	client := srv.Client(lighthouse.WithHTTPClient(&http.Client{Transport: rt}))
+	if _, err := client.Gateway().Download(ctx, c, dst); err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, dst, text)
+	if reqs := rt.requests(); reqs[0].Get("If-Range") != `"stale"` {
+		t.Errorf("If-Range = %q, want the stored ETag", reqs[0].Get("If-Range"))
+	}
+}
+
+func TestDownloadMissing(t *testing.T) {
+	srv := lighthousetest.NewServer(t)
+	dst := filepath.Join(t.TempDir(), "out")
+	_, err := srv.Client().Gateway().Download(context.Background(), "bafkreigh2akiscaildcqabsyg3dfr6chu3fgpregiymsck7e7aqa4s52zy", dst)
+	if !errors.Is(err, lighthouse.ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+	if _, err := os.Stat(dst); !errors.Is(err, os.ErrNotExist) {
+		t.Error("failed Download created the destination")
+	}
+}
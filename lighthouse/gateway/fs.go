@@ -0,0 +1,394 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/unixfs"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+// maxBlockSize bounds raw block fetches; UnixFS blocks are at most 1 MiB in
+// practice and 2 MiB by the IPFS block limit.
+const maxBlockSize = 2 << 20
+
+// FS is a read-only fs.FS over a UnixFS directory. Directory blocks are
+// fetched from the gateway as raw blocks, verified against their CIDs and
+// cached for the lifetime of the FS; file contents are read with range
+// requests, so files opened from FS implement io.Seeker and io.ReaderAt and
+// work with http.FS.
+type FS struct {
+	s    *Service
+	ctx  context.Context
+	root unixfs.CID
+
+	mu    sync.Mutex
+	nodes map[string]*fsNode
+}
+
+type fsNode struct {
+	cid     unixfs.CID
+	mode    fs.FileMode
+	size    int64
+	target  string    // symlinks
+	entries []fsEntry // directories, sorted by name
+}
+
+type fsEntry struct {
+	name  string
+	cid   unixfs.CID
+	tsize uint64
+}
+
+var (
+	_ fs.FS        = (*FS)(nil)
+	_ fs.ReadDirFS = (*FS)(nil)
+	_ fs.StatFS    = (*FS)(nil)
+)
+
+// FS returns a filesystem rooted at the directory cid, which may be a CID or
+// an /ipfs/ path. ctx governs every request made through the FS.
+func (s *Service) FS(ctx context.Context, cid string) (*FS, error) {
+	p, err := ContentPath(cid, "")
+	if err != nil {
+		return nil, err
+	}
+	root, names, err := splitIPFSPath(p)
+	if err != nil {
+		return nil, err
+	}
+	fsys := &FS{s: s, ctx: ctx, root: root, nodes: map[string]*fsNode{}}
+	n, err := fsys.node(fsEntry{cid: root})
+	for _, name := range names {
+		if err != nil {
+			break
+		}
+		n, err = fsys.child(n, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !n.mode.IsDir() {
+		return nil, fmt.Errorf("%s: not a directory", p)
+	}
+	fsys.root = n.cid
+	return fsys, nil
+}
+
+// Open implements fs.FS.
+func (f *FS) Open(name string) (fs.File, error) {
+	n, err := f.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	info := f.info(path.Base(name), n)
+	if n.mode.IsDir() {
+		return &fsDir{fsys: f, node: n, info: info}, nil
+	}
+	if n.mode&fs.ModeSymlink != 0 {
+		return &fsFile{info: info, r: strings.NewReader(n.target)}, nil
+	}
+	r := &Reader{s: f.s, ctx: f.ctx, path: "/ipfs/" + n.cid.String(), info: schema.ContentInfo{
+		Path:      "/ipfs/" + n.cid.String(),
+		Size:      n.size,
+		TotalSize: n.size,
+	}}
+	return &fsFile{info: info, r: r, c: r}, nil
+}
+
+// Stat implements fs.StatFS.
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	n, err := f.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return f.info(path.Base(name), n), nil
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	n, err := f.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !n.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	entries, err := f.entries(n)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	return entries, nil
+}
+
+func (f *FS) lookup(op, name string) (*fsNode, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	n, err := f.node(fsEntry{cid: f.root})
+	if err == nil && name != "." {
+		for _, elem := range strings.Split(name, "/") {
+			if n, err = f.child(n, elem); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return n, nil
+}
+
+func (f *FS) child(dir *fsNode, name string) (*fsNode, error) {
+	if !dir.mode.IsDir() {
+		return nil, fs.ErrNotExist
+	}
+	i := sort.Search(len(dir.entries), func(i int) bool { return dir.entries[i].name >= name })
+	if i == len(dir.entries) || dir.entries[i].name != name {
+		return nil, fs.ErrNotExist
+	}
+	return f.node(dir.entries[i])
+}
+
+func (f *FS) entries(dir *fsNode) ([]fs.DirEntry, error) {
+	out := make([]fs.DirEntry, len(dir.entries))
+	errs := make([]error, len(dir.entries))
+	sem := make(chan struct{}, 8)
+	var wg sync.WaitGroup
+	for i, e := range dir.entries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			n, err := f.node(e)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			out[i] = fs.FileInfoToDirEntry(f.info(e.name, n))
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// node resolves and caches the entry's node. Raw leaves need no request:
+// their link Tsize is their size. Tsize is optional in dag-pb, so a raw leaf
+// linked with a zero Tsize is fetched to learn its size.
+func (f *FS) node(e fsEntry) (*fsNode, error) {
+	key := string(e.cid.Bytes())
+	f.mu.Lock()
+	n, ok := f.nodes[key]
+	f.mu.Unlock()
+	if ok {
+		return n, nil
+	}
+
+	if e.cid.Codec == unixfs.CodecRaw {
+		n = &fsNode{cid: e.cid, mode: 0o444, size: int64(e.tsize)}
+		if e.tsize == 0 {
+			data, err := f.s.block(f.ctx, e.cid)
+			if err != nil {
+				return nil, err
+			}
+			n.size = int64(len(data))
+		}
+	} else {
+		var err error
+		if n, err = f.load(e.cid); err != nil {
+			return nil, err
+		}
+	}
+	f.mu.Lock()
+	f.nodes[key] = n
+	f.mu.Unlock()
+	return n, nil
+}
+
+func (f *FS) load(c unixfs.CID) (*fsNode, error) {
+	if c.Codec != unixfs.CodecDagPB {
+		return nil, fmt.Errorf("%s: unsupported codec 0x%x", c, c.Codec)
+	}
+	data, err := f.s.block(f.ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	pb, d, err := decodeUnixFS(c, data)
+	if err != nil {
+		return nil, err
+	}
+	n := &fsNode{cid: c}
+	switch d.Type {
+	case unixfs.TypeFile, unixfs.TypeRaw:
+		n.mode, n.size = 0o444, int64(d.FileSize)
+	case unixfs.TypeSymlink:
+		n.mode, n.target, n.size = fs.ModeSymlink|0o777, string(d.Data), int64(len(d.Data))
+	case unixfs.TypeDirectory:
+		n.mode = fs.ModeDir | 0o555
+		for _, l := range pb.Links {
+			n.entries = append(n.entries, fsEntry{name: l.Name, cid: l.CID, tsize: l.Tsize})
+		}
+	case unixfs.TypeHAMTShard:
+		n.mode = fs.ModeDir | 0o555
+		if n.entries, err = f.shardEntries(pb, d); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%s: unsupported UnixFS type %d", c, d.Type)
+	}
+	sort.Slice(n.entries, func(i, j int) bool { return n.entries[i].name < n.entries[j].name })
+	return n, nil
+}
+
+// shardEntries flattens a HAMT-sharded directory. Link names are a hex
+// bucket prefix, followed by the entry name for leaves; prefix-only links
+// are nested shards.
+func (f *FS) shardEntries(pb unixfs.Node, d unixfs.Data) ([]fsEntry, error) {
+	fanout := d.Fanout
+	if fanout == 0 {
+		fanout = 256
+	}
+	width := len(fmt.Sprintf("%X", fanout-1))
+	var out []fsEntry
+	for _, l := range pb.Links {
+		if len(l.Name) < width {
+			return nil, &IntegrityError{CID: l.CID.String(), Reason: "malformed shard link name"}
+		}
+		if len(l.Name) > width {
+			out = append(out, fsEntry{name: l.Name[width:], cid: l.CID, tsize: l.Tsize})
+			continue
+		}
+		data, err := f.s.block(f.ctx, l.CID)
+		if err != nil {
+			return nil, err
+		}
+		sub, sd, err := decodeUnixFS(l.CID, data)
+		if err != nil {
+			return nil, err
+		}
+		if sd.Type != unixfs.TypeHAMTShard {
+			return nil, &IntegrityError{CID: l.CID.String(), Reason: "shard link does not point to a shard"}
+		}
+		nested, err := f.shardEntries(sub, sd)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, nested...)
+	}
+	return out, nil
+}
+
+func (f *FS) info(name string, n *fsNode) *fileInfo {
+	if name == "." || name == "/" {
+		name = "."
+	}
+	return &fileInfo{name: name, node: n}
+}
+
+// block fetches a single raw block and verifies it against c.
+func (s *Service) block(ctx context.Context, c unixfs.CID) ([]byte, error) {
+	res, err := s.get(ctx, "/ipfs/"+c.String()+"?format=raw", http.Header{"Accept": {"application/vnd.ipld.raw"}})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxBlockSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxBlockSize {
+		return nil, fmt.Errorf("%s: block exceeds %d bytes", c, maxBlockSize)
+	}
+	if err := c.Verify(data); err != nil {
+		return nil, &IntegrityError{CID: c.String(), Reason: err.Error()}
+	}
+	return data, nil
+}
+
+type fileInfo struct {
+	name string
+	node *fsNode
+}
+
+func (i *fileInfo) Name() string       { return i.name }
+func (i *fileInfo) Size() int64        { return i.node.size }
+func (i *fileInfo) Mode() fs.FileMode  { return i.node.mode }
+func (i *fileInfo) ModTime() time.Time { return time.Time{} }
+func (i *fileInfo) IsDir() bool        { return i.node.mode.IsDir() }
+
+// Sys returns the entry's CID. Its type is the internal unixfs.CID, which
+// package cid exports as cid.CID, so callers can use info.Sys().(cid.CID).
+func (i *fileInfo) Sys() any { return i.node.cid }
+
+type fsFile struct {
+	info *fileInfo
+	r    interface {
+		io.ReadSeeker
+		io.ReaderAt
+	}
+	c io.Closer
+}
+
+func (f *fsFile) Stat() (fs.FileInfo, error)                { return f.info, nil }
+func (f *fsFile) Read(p []byte) (int, error)                { return f.r.Read(p) }
+func (f *fsFile) ReadAt(p []byte, off int64) (int, error)   { return f.r.ReadAt(p, off) }
+func (f *fsFile) Seek(off int64, whence int) (int64, error) { return f.r.Seek(off, whence) }
+
+func (f *fsFile) Close() error {
+	if f.c != nil {
+		return f.c.Close()
+	}
+	return nil
+}
+
+type fsDir struct {
+	fsys    *FS
+	node    *fsNode
+	info    *fileInfo
+	entries []fs.DirEntry
+	loaded  bool
+}
+
+func (d *fsDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *fsDir) Close() error               { return nil }
+
+func (d *fsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (d *fsDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.loaded {
+		entries, err := d.fsys.entries(d.node)
+		if err != nil {
+			return nil, err
+		}
+		d.entries, d.loaded = entries, true
+	}
+	if n <= 0 {
+		out := d.entries
+		d.entries = nil
+		return out, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	out := d.entries[:n]
+	d.entries = d.entries[n:]
+	return out, nil
+}
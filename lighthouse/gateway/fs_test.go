@@ -0,0 +1,165 @@
+package gateway_test
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/unixfs"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+)
+
+// putBlock stores data on srv as a block of the given codec ("raw" or
+// "dag-pb") and returns its CID.
+func putBlock(t *testing.T, srv *lighthousetest.Server, codec string, data []byte) unixfs.CID {
+	t.Helper()
+	code := uint64(unixfs.CodecRaw)
+	if codec == "dag-pb" {
+		code = unixfs.CodecDagPB
+	}
+	c := unixfs.Sum(code, data)
+	if err := srv.PutBlock(cid.Block{CID: c, Data: data}); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// Tsize is optional in dag-pb; a raw leaf linked without one must still
+// report its real size.
+func TestFSRawLeafWithoutTsize(t *testing.T) {
+	srv := lighthousetest.NewServer(t)
+	files := map[string][]byte{"sized.txt": []byte("linked with a Tsize"), "unsized.txt": []byte("linked without one")}
+	sized, unsized := unixfs.Sum(unixfs.CodecRaw, files["sized.txt"]), unixfs.Sum(unixfs.CodecRaw, files["unsized.txt"])
+	dir := unixfs.DirectoryNode([]unixfs.Link{
+		{CID: sized, Name: "sized.txt", Tsize: uint64(len(files["sized.txt"]))},
+		{CID: unsized, Name: "unsized.txt"},
+	})
+	putBlock(t, srv, "raw", files["sized.txt"])
+	putBlock(t, srv, "raw", files["unsized.txt"])
+	putBlock(t, srv, "dag-pb", dir.Data)
+
+	fsys, err := srv.Client().Gateway().FS(context.Background(), dir.CID.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		info, err := fs.Stat(fsys, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() != int64(len(content)) {
+			t.Errorf("%s: Size() = %d, want %d", name, info.Size(), len(content))
+		}
+		got, err := fs.ReadFile(fsys, name)
+		if err != nil || !bytes.Equal(got, content) {
+			t.Errorf("%s: ReadFile = %q, %v", name, got, err)
+		}
+	}
+}
+
+func TestFSMultiLevel(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	root := filepath.Join(t.TempDir(), "site")
+	files := map[string]string{
+		"index.html":              "<h1>home</h1>",
+		"docs/guide.txt":          "guide",
+		"docs/img/logo.svg":       "<svg/>",
+		"docs/img/icons/16.txt":   "sixteen",
+		"assets/css/style.css":    "body{}",
+		"assets/js/app/main.js":   "main()",
+		"assets/js/app/vendor.js": "vendor()",
+	}
+	for rel, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := srv.Client().Storage().UploadDirectory(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fsys, err := srv.Client().Gateway().FS(ctx, res.Root.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for rel := range files {
+		names = append(names, rel)
+	}
+	if err := fstest.TestFS(fsys, names...); err != nil {
+		t.Fatal(err)
+	}
+
+	// A subdirectory can be the root of its own FS.
+	sub, err := srv.Client().Gateway().FS(ctx, "/ipfs/"+res.Root.Hash+"/docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(sub, "guide.txt", "img/logo.svg", "img/icons/16.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := fs.Stat(fsys, "docs/guide.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := cid.Compute(bytes.NewReader([]byte("guide")))
+	if c, ok := info.Sys().(cid.CID); !ok || !c.Equal(want) {
+		t.Errorf("Sys() = %v, want cid.CID %s", info.Sys(), want)
+	}
+}
+
+// shardData encodes a HAMT shard's UnixFS Data with a fanout of 256 and the
+// murmur3 hash type; Data.Encode does not write those fields.
+func shardData() []byte {
+	d := unixfs.Data{Type: unixfs.TypeHAMTShard}.Encode()
+	return append(d, 0x30, 0x80, 0x02, 0x38, 0x22)
+}
+
+// The FS flattens sharded directories by link name and does not depend on
+// the entries being in their hashed buckets, so the layout here is arbitrary.
+func TestFSShardedDirectory(t *testing.T) {
+	srv := lighthousetest.NewServer(t)
+	file := func(content string) unixfs.Link {
+		return unixfs.Link{CID: putBlock(t, srv, "raw", []byte(content)), Tsize: uint64(len(content))}
+	}
+	link := func(name string, l unixfs.Link) unixfs.Link {
+		l.Name = name
+		return l
+	}
+
+	subdir := unixfs.DirectoryNode([]unixfs.Link{link("d.txt", file("dee"))})
+	putBlock(t, srv, "dag-pb", subdir.Data)
+	nested := unixfs.Node{Data: shardData(), Links: []unixfs.Link{
+		link("07c.txt", file("sea")),
+		link("E4e.txt", file("eee")),
+	}}.Encode()
+	nestedCID := putBlock(t, srv, "dag-pb", nested)
+	top := unixfs.Node{Data: shardData(), Links: []unixfs.Link{
+		link("1Fa.txt", file("ay")),
+		{Name: "3A", CID: nestedCID, Tsize: uint64(len(nested))},
+		link("9Bb.txt", file("bee")),
+		{Name: "C2sub", CID: subdir.CID, Tsize: uint64(len(subdir.Data))},
+	}}.Encode()
+	root := putBlock(t, srv, "dag-pb", top)
+
+	fsys, err := srv.Client().Gateway().FS(context.Background(), root.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(fsys, "a.txt", "b.txt", "c.txt", "e.txt", "sub/d.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := fs.ReadFile(fsys, "e.txt"); err != nil || string(got) != "eee" {
+		t.Errorf("e.txt = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,121 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
+)
+
+// ErrRangeNotSupported is returned when the gateway answers a ranged request
+// with the whole object, either because it ignores Range or because the
+// content behind an /ipns/ name changed since the first read.
+var ErrRangeNotSupported = errors.New("gateway ignored range request")
+
+// GetRange fetches length bytes starting at offset. A negative length reads
+// to the end of the object. The caller must close the returned reader.
+func (s *Service) GetRange(ctx context.Context, cid string, offset, length int64) (_ io.ReadCloser, _ *schema.ContentInfo, err error) {
+	ctx, op := s.h.StartOp(ctx, "gateway.GetRange", telemetry.String(telemetry.AttrCID, cid))
+	defer func() { op.End(err) }()
+
+	p, err := ContentPath(cid, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	res, info, err := s.fetchRange(ctx, p, offset, length, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if res.StatusCode != http.StatusPartialContent && offset > 0 {
+		res.Body.Close()
+		return nil, nil, ErrRangeNotSupported
+	}
+	return res.Body, info, nil
+}
+
+// fetchRange requests [offset, offset+length) of p. When ifRange is set the
+// range only applies if the object still has that ETag. A 200 response means
+// the range was not applied; for offset 0 its body is trimmed to length.
+func (s *Service) fetchRange(ctx context.Context, p string, offset, length int64, ifRange string) (*http.Response, *schema.ContentInfo, error) {
+	if offset < 0 || length == 0 {
+		return nil, nil, fmt.Errorf("invalid range: offset=%d length=%d", offset, length)
+	}
+	header := http.Header{}
+	if offset > 0 || length > 0 {
+		r := "bytes=" + strconv.FormatInt(offset, 10) + "-"
+		if length > 0 {
+			r += strconv.FormatInt(offset+length-1, 10)
+		}
+		header.Set("Range", r)
+		if ifRange != "" {
+			header.Set("If-Range", ifRange)
+		}
+	}
+	res, err := s.get(ctx, p, header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info := &schema.ContentInfo{
+		Path:        p,
+		Size:        res.ContentLength,
+		TotalSize:   res.ContentLength,
+		ContentType: res.Header.Get("Content-Type"),
+		ETag:        res.Header.Get("ETag"),
+	}
+	if res.StatusCode != http.StatusPartialContent {
+		if length > 0 && offset == 0 {
+			res.Body = readCloser{Reader: io.LimitReader(res.Body, length), Closer: res.Body}
+			if info.Size < 0 || info.Size > length {
+				info.Size = length
+			}
+		}
+		return res, info, nil
+	}
+
+	start, end, total, ok := parseContentRange(res.Header.Get("Content-Range"))
+	if !ok || start != offset {
+		res.Body.Close()
+		return nil, nil, fmt.Errorf("unexpected Content-Range %q for offset %d", res.Header.Get("Content-Range"), offset)
+	}
+	info.Offset = start
+	info.Size = end - start + 1
+	info.TotalSize = total
+	return res, info, nil
+}
+
+// parseContentRange parses "bytes start-end/total"; total is -1 for "*".
+func parseContentRange(v string) (start, end, total int64, ok bool) {
+	v, found := strings.CutPrefix(v, "bytes ")
+	if !found {
+		return 0, 0, 0, false
+	}
+	rng, size, found := strings.Cut(v, "/")
+	if !found {
+		return 0, 0, 0, false
+	}
+	first, last, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, 0, false
+	}
+	var err error
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, 0, false
+	}
+	if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+		return 0, 0, 0, false
+	}
+	total = -1
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil || total <= end {
+			return 0, 0, 0, false
+		}
+	}
+	return start, end, total, true
+}
@@ -0,0 +1,159 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+// Reader gives random access to gateway content through range requests. It
+// implements io.ReadSeeker, io.ReaderAt and io.Closer. Sequential Reads share
+// one streaming response; ReadAt issues a request per call and is safe for
+// concurrent use. Requests are pinned to the ETag seen when the Reader was
+// opened, so content behind an /ipns/ name changing mid-read surfaces as
+// ErrRangeNotSupported instead of mixed data.
+type Reader struct {
+	s    *Service
+	ctx  context.Context
+	path string
+	info schema.ContentInfo
+
+	off     int64
+	body    io.ReadCloser
+	bodyOff int64
+}
+
+// NewReader opens cid (a CID or /ipfs/, /ipns/ path) for random access. ctx
+// governs every request the Reader makes.
+func (s *Service) NewReader(ctx context.Context, cid string) (*Reader, error) {
+	p, err := ContentPath(cid, "")
+	if err != nil {
+		return nil, err
+	}
+	res, info, err := s.fetchRange(ctx, p, 0, 1, "")
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+	if info.TotalSize < 0 {
+		return nil, errors.New("gateway did not report content size")
+	}
+	info.Size = info.TotalSize
+	info.Offset = 0
+	return &Reader{s: s, ctx: ctx, path: p, info: *info}, nil
+}
+
+// Size returns the length of the content.
+func (r *Reader) Size() int64 { return r.info.TotalSize }
+
+// Info returns the metadata reported when the Reader was opened.
+func (r *Reader) Info() schema.ContentInfo { return r.info }
+
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.off >= r.info.TotalSize {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	policy := r.s.h.RetryPolicy()
+	for attempt := 1; ; attempt++ {
+		if r.body == nil || r.bodyOff != r.off {
+			if err := r.open(); err != nil {
+				return 0, err
+			}
+		}
+		n, err := r.body.Read(p)
+		r.off += int64(n)
+		r.bodyOff += int64(n)
+		if err == io.EOF && r.off >= r.info.TotalSize {
+			return n, nil
+		}
+		if err == nil {
+			return n, nil
+		}
+		r.closeBody()
+		if r.ctx.Err() != nil || !policy.Retryable(nil, err) || attempt >= policy.MaxAttempts {
+			return n, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (r *Reader) open() error {
+	r.closeBody()
+	res, _, err := r.s.fetchRange(r.ctx, r.path, r.off, -1, r.info.ETag)
+	if err != nil {
+		return err
+	}
+	if r.off > 0 && res.StatusCode != http.StatusPartialContent {
+		res.Body.Close()
+		return ErrRangeNotSupported
+	}
+	r.body, r.bodyOff = res.Body, r.off
+	return nil
+}
+
+func (r *Reader) closeBody() {
+	if r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+}
+
+// ReadAt reads len(p) bytes at off with a single range request.
+func (r *Reader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("gateway.Reader.ReadAt: negative offset")
+	}
+	if off >= r.info.TotalSize {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	want := int64(len(p))
+	if rest := r.info.TotalSize - off; want > rest {
+		want = rest
+	}
+	res, _, err := r.s.fetchRange(r.ctx, r.path, off, want, r.info.ETag)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if off > 0 && res.StatusCode != http.StatusPartialContent {
+		return 0, ErrRangeNotSupported
+	}
+	n, err := io.ReadFull(res.Body, p[:want])
+	if err == nil && want < int64(len(p)) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.off
+	case io.SeekEnd:
+		offset += r.info.TotalSize
+	default:
+		return 0, errors.New("gateway.Reader.Seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("gateway.Reader.Seek: negative position")
+	}
+	r.off = offset
+	return offset, nil
+}
+
+func (r *Reader) Close() error {
+	r.closeBody()
+	return nil
+}
@@ -0,0 +1,142 @@
+package gateway_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+)
+
+const alphabet = "abcdefghijklmnopqrstuvwxyz"
+
+func TestGetRange(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	c := uploadText(t, srv, "abc.txt", alphabet)
+	gw := srv.Client().Gateway()
+
+	for _, tc := range []struct {
+		offset, length int64
+		want           string
+	}{
+		{0, 5, "abcde"},
+		{10, 3, "klm"},
+		{20, -1, "uvwxyz"},
+		{24, 10, "yz"},
+	} {
+		rc, info, err := gw.GetRange(ctx, c, tc.offset, tc.length)
+		if err != nil {
+			t.Fatalf("GetRange(%d, %d): %v", tc.offset, tc.length, err)
+		}
+		got, _ := io.ReadAll(rc)
+		rc.Close()
+		if string(got) != tc.want {
+			t.Errorf("GetRange(%d, %d) = %q, want %q", tc.offset, tc.length, got, tc.want)
+		}
+		if tc.offset > 0 && (info.Offset != tc.offset || info.Size != int64(len(tc.want)) || info.TotalSize != int64(len(alphabet))) {
+			t.Errorf("GetRange(%d, %d) info = %+v", tc.offset, tc.length, info)
+		}
+	}
+
+	if _, _, err := gw.GetRange(ctx, c, -1, 5); err == nil {
+		t.Error("negative offset: want an error")
+	}
+}
+
+// A gateway that answers a ranged request with the whole object is reported
+// rather than returning the wrong bytes.
+func TestGetRangeIgnored(t *testing.T) {
+	srv := lighthousetest.NewServer(t)
+	c := uploadText(t, srv, "abc.txt", alphabet)
+	noRange := roundTrip(func(r *http.Request) (*http.Response, error) {
+		r = r.Clone(r.Context())
+		r.Header.Del("Range")
+		return http.DefaultTransport.RoundTrip(r)
+	})
+	client := srv.Client(lighthouse.WithHTTPClient(&http.Client{Transport: noRange}))
+	if _, _, err := client.Gateway().GetRange(context.Background(), c, 10, 3); !errors.Is(err, lighthouse.ErrRangeNotSupported) {
+		t.Errorf("err = %v, want ErrRangeNotSupported", err)
+	}
+}
+
+type roundTrip func(*http.Request) (*http.Response, error)
+
+func (f roundTrip) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestNewReader(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	c := uploadText(t, srv, "abc.txt", alphabet)
+	r, err := srv.Client().Gateway().NewReader(ctx, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.Size() != int64(len(alphabet)) {
+		t.Errorf("Size() = %d, want %d", r.Size(), len(alphabet))
+	}
+
+	buf := make([]byte, 4)
+	if n, err := r.ReadAt(buf, 22); n != 4 || err != nil || string(buf) != "wxyz" {
+		t.Errorf("ReadAt(22) = %d, %v, %q", n, err, buf)
+	}
+	if n, err := r.ReadAt(buf, 24); n != 2 || err != io.EOF || string(buf[:n]) != "yz" {
+		t.Errorf("ReadAt(24) = %d, %v, %q; want a short read with io.EOF", n, err, buf[:n])
+	}
+
+	if _, err := r.Seek(-6, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := io.ReadAll(r); string(got) != "uvwxyz" {
+		t.Errorf("read after Seek = %q", got)
+	}
+	if _, err := r.Seek(3, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(r, buf); err != nil || string(buf) != "defg" {
+		t.Errorf("ReadFull after Seek = %q, %v", buf, err)
+	}
+
+	// ReadAt is safe for concurrent use.
+	var wg sync.WaitGroup
+	for i := range len(alphabet) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b := make([]byte, 1)
+			if _, err := r.ReadAt(b, int64(i)); err != nil || b[0] != alphabet[i] {
+				t.Errorf("ReadAt(%d) = %q, %v", i, b, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Sequential reads survive a body that breaks off mid-stream.
+func TestReaderResumesBrokenBody(t *testing.T) {
+	srv := lighthousetest.NewServer(t)
+	text := strings.Repeat(alphabet, 100)
+	c := uploadText(t, srv, "abc.txt", text)
+	rt := &cutTransport{limit: -1}
+	client := srv.Client(lighthouse.WithHTTPClient(&http.Client{Transport: rt}))
+	r, err := client.Gateway().NewReader(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	rt.cut(700, io.ErrUnexpectedEOF)
+	got, err := io.ReadAll(r)
+	if err != nil || string(got) != text {
+		t.Errorf("ReadAll = %d bytes, %v; want %d bytes", len(got), err, len(text))
+	}
+	reqs := rt.requests()
+	if r := reqs[len(reqs)-1].Get("Range"); r != "bytes=700-" {
+		t.Errorf("resume Range = %q, want bytes=700-", r)
+	}
+}
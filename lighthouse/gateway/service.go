@@ -0,0 +1,130 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/encryption"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/cfg"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/httpx"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
+)
+
+type Service struct {
+	h   *httpx.Client
+	cfg cfg.Config
+}
+
+func New(h *httpx.Client, c cfg.Config) *Service {
+	return &Service{h: h, cfg: c}
+}
+
+// ContentPath builds a gateway path from a CID, an /ipfs/ or /ipns/ path,
+// or an ipfs:// / ipns:// URL, plus an optional subpath.
+func ContentPath(cidOrPath, subpath string) (string, error) {
+	p := strings.TrimSpace(cidOrPath)
+	switch {
+	case strings.HasPrefix(p, "ipfs://"):
+		p = "/ipfs/" + strings.TrimPrefix(p, "ipfs://")
+	case strings.HasPrefix(p, "ipns://"):
+		p = "/ipns/" + strings.TrimPrefix(p, "ipns://")
+	case strings.HasPrefix(p, "/ipfs/"), strings.HasPrefix(p, "/ipns/"):
+	case p == "" || strings.Contains(p, "/"):
+		return "", fmt.Errorf("invalid content path %q", cidOrPath)
+	default:
+		p = "/ipfs/" + p
+	}
+	if sub := strings.Trim(subpath, "/"); sub != "" {
+		p = strings.TrimRight(p, "/") + "/" + sub
+	}
+
+	parts := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	if len(parts) < 2 || parts[1] == "" {
+		return "", fmt.Errorf("invalid content path %q", cidOrPath)
+	}
+	for i, seg := range parts {
+		if seg == "." || seg == ".." {
+			return "", fmt.Errorf("invalid content path %q", cidOrPath)
+		}
+		parts[i] = url.PathEscape(seg)
+	}
+	return "/" + strings.Join(parts, "/"), nil
+}
+
+// Get fetches content from the gateway. cid may be a bare CID, an /ipfs/ or
+// /ipns/ path, or an ipfs:// / ipns:// URL; subpath selects a file inside a
+// directory. The caller must close the returned reader.
+func (s *Service) Get(ctx context.Context, cid, subpath string, opts ...schema.DownloadOption) (_ io.ReadCloser, _ *schema.ContentInfo, err error) {
+	ctx, op := s.h.StartOp(ctx, "gateway.Get", telemetry.String(telemetry.AttrCID, cid))
+	defer func() { op.End(err) }()
+
+	o := schema.DefaultDownloadOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.DecryptKey != nil && len(o.DecryptKey) != encryption.KeySize {
+		return nil, nil, encryption.ErrKeySize
+	}
+
+	p, err := ContentPath(cid, subpath)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := s.get(ctx, p, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info := &schema.ContentInfo{
+		Path:        p,
+		Size:        res.ContentLength,
+		TotalSize:   res.ContentLength,
+		ContentType: res.Header.Get("Content-Type"),
+		ETag:        res.Header.Get("ETag"),
+	}
+	var body io.ReadCloser = res.Body
+	if o.DecryptKey != nil {
+		dr, err := encryption.NewDecryptReader(res.Body, o.DecryptKey)
+		if err != nil {
+			res.Body.Close()
+			return nil, nil, err
+		}
+		body = readCloser{Reader: dr, Closer: res.Body}
+		info.Size, info.TotalSize = -1, -1
+	}
+	return body, info, nil
+}
+
+// get issues a GET for a gateway path, retrying per the client policy, and
+// returns the successful response.
+func (s *Service) get(ctx context.Context, p string, header http.Header) (*http.Response, error) {
+	u := strings.TrimRight(s.cfg.Hosts.Gateway, "/") + p
+	res, err := s.h.Retry(ctx, func(int) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := httpx.CheckResponse(res); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	return res, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,355 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/car"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/encryption"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/unixfs"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
+)
+
+// ErrIntegrity is the category of every IntegrityError, for errors.Is.
+var ErrIntegrity = errors.New("gateway content failed verification")
+
+// IntegrityError reports gateway data that does not match the requested CID.
+type IntegrityError struct {
+	CID    string
+	Reason string
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("lighthouse: integrity check failed for %s: %s", e.CID, e.Reason)
+}
+
+func (e *IntegrityError) Unwrap() error { return ErrIntegrity }
+
+const (
+	carAccept = "application/vnd.ipld.car; version=1; order=dfs; dups=y"
+
+	// maxStash bounds blocks buffered because the gateway sent them before
+	// they were needed.
+	maxStash = 64 << 20
+
+	// recentBlocks are kept after use so repeated chunks survive a gateway
+	// that ignores dups=y.
+	recentBlocks = 32
+)
+
+// GetVerified fetches a file as a CAR and streams its content while checking
+// every block against the requested CID, so the gateway does not have to be
+// trusted. cid must be a CID or /ipfs/ path; subpath is resolved through
+// verified directory blocks. Errors from a mismatch wrap ErrIntegrity.
+func (s *Service) GetVerified(ctx context.Context, cid, subpath string, opts ...schema.DownloadOption) (_ io.ReadCloser, _ *schema.ContentInfo, err error) {
+	ctx, op := s.h.StartOp(ctx, "gateway.GetVerified", telemetry.String(telemetry.AttrCID, cid))
+	defer func() { op.End(err) }()
+
+	o := schema.DefaultDownloadOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.DecryptKey != nil && len(o.DecryptKey) != encryption.KeySize {
+		return nil, nil, encryption.ErrKeySize
+	}
+	p, err := ContentPath(cid, subpath)
+	if err != nil {
+		return nil, nil, err
+	}
+	vr, info, err := s.getVerified(ctx, p)
+	if err != nil {
+		return nil, nil, err
+	}
+	if o.DecryptKey == nil {
+		return vr, info, nil
+	}
+	dr, err := encryption.NewDecryptReader(vr, o.DecryptKey)
+	if err != nil {
+		vr.Close()
+		return nil, nil, err
+	}
+	info.Size, info.TotalSize = -1, -1
+	return readCloser{Reader: dr, Closer: vr}, info, nil
+}
+
+func (s *Service) getVerified(ctx context.Context, p string) (*verifiedReader, *schema.ContentInfo, error) {
+	root, names, err := splitIPFSPath(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := s.get(ctx, p+"?format=car&dag-scope=entity", http.Header{"Accept": {carAccept}})
+	if err != nil {
+		return nil, nil, err
+	}
+	if ct := res.Header.Get("Content-Type"); ct != "" && !strings.HasPrefix(ct, "application/vnd.ipld.car") {
+		res.Body.Close()
+		return nil, nil, fmt.Errorf("gateway returned %q instead of a CAR", ct)
+	}
+	cr, err := car.NewReader(res.Body)
+	if err != nil {
+		res.Body.Close()
+		return nil, nil, err
+	}
+
+	vr := &verifiedReader{
+		body:  res.Body,
+		store: &blockStore{r: cr, stash: map[string][]byte{}},
+	}
+	target, err := vr.resolve(root, names)
+	if err == nil {
+		err = vr.start(target)
+	}
+	if err != nil {
+		res.Body.Close()
+		return nil, nil, err
+	}
+	return vr, &schema.ContentInfo{
+		Path:      p,
+		Size:      int64(vr.size),
+		TotalSize: int64(vr.size),
+		ETag:      res.Header.Get("ETag"),
+	}, nil
+}
+
+// splitIPFSPath splits an escaped /ipfs/<cid>/a/b path into the root CID and
+// unescaped segment names.
+func splitIPFSPath(p string) (unixfs.CID, []string, error) {
+	rest, ok := strings.CutPrefix(p, "/ipfs/")
+	if !ok {
+		return unixfs.CID{}, nil, fmt.Errorf("verified fetch needs an /ipfs/ path, got %q", p)
+	}
+	parts := strings.Split(rest, "/")
+	root, err := unixfs.Parse(parts[0])
+	if err != nil {
+		return unixfs.CID{}, nil, err
+	}
+	var names []string
+	for _, seg := range parts[1:] {
+		name, err := url.PathUnescape(seg)
+		if err != nil {
+			return unixfs.CID{}, nil, err
+		}
+		names = append(names, name)
+	}
+	return root, names, nil
+}
+
+// blockStore hands out verified blocks by CID from a CAR stream. Blocks that
+// arrive before they are needed are stashed.
+type blockStore struct {
+	r          *car.Reader
+	stash      map[string][]byte
+	stashBytes int
+	recent     []unixfs.Block
+}
+
+func (b *blockStore) get(c unixfs.CID) ([]byte, error) {
+	if code, digest, err := c.Digest(); err == nil && code == 0x00 {
+		return digest, nil // identity multihash: data is inline
+	}
+	key := string(c.Bytes())
+	if data, ok := b.stash[key]; ok {
+		delete(b.stash, key)
+		b.stashBytes -= len(data)
+		b.remember(c, data)
+		return data, nil
+	}
+	for _, blk := range b.recent {
+		if blk.CID.Equal(c) {
+			return blk.Data, nil
+		}
+	}
+	for {
+		blk, err := b.r.Next()
+		if err == io.EOF {
+			return nil, &IntegrityError{CID: c.String(), Reason: "block missing from response"}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := blk.CID.Verify(blk.Data); err != nil {
+			return nil, &IntegrityError{CID: blk.CID.String(), Reason: err.Error()}
+		}
+		if blk.CID.Equal(c) {
+			b.remember(c, blk.Data)
+			return blk.Data, nil
+		}
+		k := string(blk.CID.Bytes())
+		if _, dup := b.stash[k]; dup {
+			continue
+		}
+		b.stash[k] = blk.Data
+		b.stashBytes += len(blk.Data)
+		if b.stashBytes > maxStash {
+			return nil, fmt.Errorf("gateway sent more than %d bytes of blocks out of order", maxStash)
+		}
+	}
+}
+
+func (b *blockStore) remember(c unixfs.CID, data []byte) {
+	if len(b.recent) == recentBlocks {
+		copy(b.recent, b.recent[1:])
+		b.recent = b.recent[:recentBlocks-1]
+	}
+	b.recent = append(b.recent, unixfs.Block{CID: c, Data: data})
+}
+
+// verifiedReader walks a UnixFS file depth-first, emitting leaf data as each
+// verified block arrives.
+type verifiedReader struct {
+	body  io.Closer
+	store *blockStore
+
+	root  unixfs.CID
+	size  uint64
+	read  uint64
+	stack []unixfs.CID
+	buf   []byte
+	err   error
+}
+
+// resolve follows names from root through plain UnixFS directories.
+func (v *verifiedReader) resolve(root unixfs.CID, names []string) (unixfs.CID, error) {
+	cur := root
+	for _, name := range names {
+		if cur.Codec != unixfs.CodecDagPB {
+			return unixfs.CID{}, fmt.Errorf("%s: not a directory", cur)
+		}
+		data, err := v.store.get(cur)
+		if err != nil {
+			return unixfs.CID{}, err
+		}
+		n, fs, err := decodeUnixFS(cur, data)
+		if err != nil {
+			return unixfs.CID{}, err
+		}
+		switch fs.Type {
+		case unixfs.TypeDirectory:
+		case unixfs.TypeHAMTShard:
+			return unixfs.CID{}, fmt.Errorf("%s: sharded directories are not supported for verified fetch", cur)
+		default:
+			return unixfs.CID{}, fmt.Errorf("%s: not a directory", cur)
+		}
+		found := false
+		for _, l := range n.Links {
+			if l.Name == name {
+				cur, found = l.CID, true
+				break
+			}
+		}
+		if !found {
+			return unixfs.CID{}, fmt.Errorf("%s: no link named %q", cur, name)
+		}
+	}
+	return cur, nil
+}
+
+// start loads the file root and records its declared size.
+func (v *verifiedReader) start(c unixfs.CID) error {
+	v.root = c
+	if c.Codec == unixfs.CodecRaw {
+		data, err := v.store.get(c)
+		if err != nil {
+			return err
+		}
+		v.size, v.buf = uint64(len(data)), data
+		return nil
+	}
+	if c.Codec != unixfs.CodecDagPB {
+		return fmt.Errorf("%s: unsupported codec 0x%x", c, c.Codec)
+	}
+	data, err := v.store.get(c)
+	if err != nil {
+		return err
+	}
+	n, fs, err := decodeUnixFS(c, data)
+	if err != nil {
+		return err
+	}
+	if fs.Type != unixfs.TypeFile && fs.Type != unixfs.TypeRaw {
+		return fmt.Errorf("%s: not a file", c)
+	}
+	v.size = fs.FileSize
+	v.push(n.Links)
+	v.buf = fs.Data
+	return nil
+}
+
+func (v *verifiedReader) push(links []unixfs.Link) {
+	for i := len(links) - 1; i >= 0; i-- {
+		v.stack = append(v.stack, links[i].CID)
+	}
+}
+
+func (v *verifiedReader) Read(p []byte) (int, error) {
+	for len(v.buf) == 0 {
+		if v.err != nil {
+			return 0, v.err
+		}
+		if len(v.stack) == 0 {
+			if v.read != v.size {
+				v.err = &IntegrityError{CID: v.root.String(), Reason: fmt.Sprintf("file is %d bytes, root declares %d", v.read, v.size)}
+			} else {
+				v.err = io.EOF
+			}
+			return 0, v.err
+		}
+		c := v.stack[len(v.stack)-1]
+		v.stack = v.stack[:len(v.stack)-1]
+		if v.buf, v.err = v.next(c); v.err != nil {
+			return 0, v.err
+		}
+	}
+	n := copy(p, v.buf)
+	v.buf = v.buf[n:]
+	v.read += uint64(n)
+	if v.read > v.size {
+		v.err = &IntegrityError{CID: v.root.String(), Reason: fmt.Sprintf("file exceeds declared size %d", v.size)}
+		return 0, v.err
+	}
+	return n, nil
+}
+
+// next loads c, queues its children and returns its inline data.
+func (v *verifiedReader) next(c unixfs.CID) ([]byte, error) {
+	data, err := v.store.get(c)
+	if err != nil {
+		return nil, err
+	}
+	switch c.Codec {
+	case unixfs.CodecRaw:
+		return data, nil
+	case unixfs.CodecDagPB:
+		n, fs, err := decodeUnixFS(c, data)
+		if err != nil {
+			return nil, err
+		}
+		if fs.Type != unixfs.TypeFile && fs.Type != unixfs.TypeRaw {
+			return nil, &IntegrityError{CID: c.String(), Reason: "unexpected node type inside file"}
+		}
+		v.push(n.Links)
+		return fs.Data, nil
+	default:
+		return nil, &IntegrityError{CID: c.String(), Reason: fmt.Sprintf("unsupported codec 0x%x inside file", c.Codec)}
+	}
+}
+
+func (v *verifiedReader) Close() error { return v.body.Close() }
+
+func decodeUnixFS(c unixfs.CID, data []byte) (unixfs.Node, unixfs.Data, error) {
+	n, err := unixfs.DecodeNode(data)
+	if err != nil {
+		return unixfs.Node{}, unixfs.Data{}, &IntegrityError{CID: c.String(), Reason: err.Error()}
+	}
+	fs, err := unixfs.DecodeData(n.Data)
+	if err != nil {
+		return unixfs.Node{}, unixfs.Data{}, &IntegrityError{CID: c.String(), Reason: err.Error()}
+	}
+	return n, fs, nil
+}
@@ -0,0 +1,178 @@
+package gateway_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/unixfs"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+// putFile stores chunks as raw leaves under a balanced tree of the given
+// width and returns the root. Leaves listed in skip are not stored.
+func putFile(t *testing.T, srv *lighthousetest.Server, chunks [][]byte, width int, skip ...int) unixfs.CID {
+	t.Helper()
+	var leaves []unixfs.Child
+	for i, c := range chunks {
+		leaf := unixfs.Sum(unixfs.CodecRaw, c)
+		leaves = append(leaves, unixfs.Child{CID: leaf, FileSize: uint64(len(c)), Tsize: uint64(len(c))})
+		if !slices.Contains(skip, i) {
+			putBlock(t, srv, "raw", c)
+		}
+	}
+	root, blocks := unixfs.Balanced(leaves, width)
+	for _, b := range blocks {
+		putBlock(t, srv, "dag-pb", b.Data)
+	}
+	return root.CID
+}
+
+func testChunks(n int) ([][]byte, string) {
+	var chunks [][]byte
+	for i := range n {
+		chunks = append(chunks, []byte(strings.Repeat(fmt.Sprint(i), 100+i)))
+	}
+	return chunks, string(bytes.Join(chunks, nil))
+}
+
+func readVerified(t *testing.T, client *lighthouse.Client, c string) ([]byte, error) {
+	t.Helper()
+	rc, _, err := client.Gateway().GetVerified(context.Background(), c, "")
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// tamper flips the last byte of CAR responses, which lands in the last
+// block's data.
+type tamper struct{}
+
+func (tamper) RoundTrip(r *http.Request) (*http.Response, error) {
+	res, err := http.DefaultTransport.RoundTrip(r)
+	if err != nil || r.URL.Query().Get("format") != "car" {
+		return res, err
+	}
+	b, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	b[len(b)-1] ^= 0xff
+	res.Body = io.NopCloser(bytes.NewReader(b))
+	return res, nil
+}
+
+func TestGetVerifiedMultiBlock(t *testing.T) {
+	srv := lighthousetest.NewServer(t)
+	chunks, want := testChunks(7)
+	root := putFile(t, srv, chunks, 2)
+
+	got, err := readVerified(t, srv.Client(), root.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("GetVerified returned %d bytes, want %d", len(got), len(want))
+	}
+
+	// Subpaths resolve through verified directory blocks.
+	dir := unixfs.DirectoryNode([]unixfs.Link{{CID: root, Name: "file.bin"}})
+	putBlock(t, srv, "dag-pb", dir.Data)
+	rc, info, err := srv.Client().Gateway().GetVerified(context.Background(), dir.CID.String(), "file.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ = io.ReadAll(rc)
+	rc.Close()
+	if string(got) != want || info.Size != int64(len(want)) {
+		t.Errorf("subpath: %d bytes, info.Size %d; want %d", len(got), info.Size, len(want))
+	}
+}
+
+func TestGetVerifiedTamperedBlock(t *testing.T) {
+	srv := lighthousetest.NewServer(t)
+	chunks, _ := testChunks(4)
+	root := putFile(t, srv, chunks, 2)
+	client := srv.Client(lighthouse.WithHTTPClient(&http.Client{Transport: tamper{}}))
+
+	_, err := readVerified(t, client, root.String())
+	var ie *lighthouse.IntegrityError
+	if !errors.Is(err, lighthouse.ErrIntegrity) || !errors.As(err, &ie) {
+		t.Fatalf("err = %v, want an IntegrityError", err)
+	}
+	if want := unixfs.Sum(unixfs.CodecRaw, chunks[3]).String(); ie.CID != want {
+		t.Errorf("IntegrityError.CID = %s, want the last leaf %s", ie.CID, want)
+	}
+}
+
+func TestGetVerifiedMissingBlock(t *testing.T) {
+	srv := lighthousetest.NewServer(t)
+	chunks, _ := testChunks(4)
+	root := putFile(t, srv, chunks, 2, 2)
+
+	if _, err := readVerified(t, srv.Client(), root.String()); !errors.Is(err, lighthouse.ErrIntegrity) {
+		t.Errorf("err = %v, want ErrIntegrity", err)
+	}
+}
+
+func TestGetVerifiedSizeMismatch(t *testing.T) {
+	chunks, _ := testChunks(2)
+	for _, declared := range []uint64{50, 1000} {
+		srv := lighthousetest.NewServer(t)
+		n := unixfs.Node{}
+		for _, c := range chunks {
+			putBlock(t, srv, "raw", c)
+			n.Links = append(n.Links, unixfs.Link{CID: unixfs.Sum(unixfs.CodecRaw, c), Tsize: uint64(len(c))})
+		}
+		n.Data = unixfs.Data{Type: unixfs.TypeFile, FileSize: declared}.Encode()
+		enc := n.Encode()
+		putBlock(t, srv, "dag-pb", enc)
+
+		_, err := readVerified(t, srv.Client(), unixfs.Sum(unixfs.CodecDagPB, enc).String())
+		if !errors.Is(err, lighthouse.ErrIntegrity) {
+			t.Errorf("declared %d bytes: err = %v, want ErrIntegrity", declared, err)
+		}
+	}
+}
+
+func TestDownloadVerified(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	chunks, want := testChunks(5)
+	root := putFile(t, srv, chunks, 2)
+	dir := t.TempDir()
+
+	dst := filepath.Join(dir, "good")
+	// A stale partial is discarded: verified downloads start from zero.
+	os.WriteFile(dst+".part", []byte("stale"), 0o644)
+	info, err := srv.Client().Gateway().Download(ctx, root.String(), dst, schema.WithVerifiedDownload())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, dst, want)
+	if info.Size != int64(len(want)) {
+		t.Errorf("info.Size = %d, want %d", info.Size, len(want))
+	}
+
+	dst = filepath.Join(dir, "bad")
+	client := srv.Client(lighthouse.WithHTTPClient(&http.Client{Transport: tamper{}}))
+	if _, err := client.Gateway().Download(ctx, root.String(), dst, schema.WithVerifiedDownload()); !errors.Is(err, lighthouse.ErrIntegrity) {
+		t.Errorf("tampered: err = %v, want ErrIntegrity", err)
+	}
+	if _, err := os.Stat(dst); !errors.Is(err, os.ErrNotExist) {
+		t.Error("tampered download created the destination")
+	}
+}
@@ -0,0 +1,178 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxErrorBody caps how much of an error response is buffered into Error.Body.
+const maxErrorBody = 64 << 10
+
+// Sentinel categories matched by errors.Is against an *Error.
+var (
+	ErrUnauthorized  = errors.New("lighthouse: unauthorized")
+	ErrQuotaExceeded = errors.New("lighthouse: quota exceeded")
+	ErrNotFound      = errors.New("lighthouse: not found")
+	ErrRateLimited   = errors.New("lighthouse: rate limited")
+	ErrServerError   = errors.New("lighthouse: server error")
+)
+
+// Error is a non-2xx response from a Lighthouse endpoint.
+type Error struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Body    []byte `json:"-"`
+}
+
+func (e *Error) Error() string {
+	if e == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("lighthouse: status=%d code=%s msg=%s", e.Status, e.Code, e.Message)
+}
+
+// Unwrap returns the sentinel category of the error (nil if uncategorised),
+// so errors.Is(err, ErrNotFound) and friends work through wrapping.
+func (e *Error) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return category(e.Status, e.Code, e.Message)
+}
+
+func category(status int, code, msg string) error {
+	lc := strings.ToLower(code + " " + msg)
+	switch {
+	case status == http.StatusPaymentRequired,
+		status == http.StatusRequestEntityTooLarge && strings.Contains(lc, "limit"):
+		return ErrQuotaExceeded
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return ErrUnauthorized
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status >= 500:
+		return ErrServerError
+	// Other 4xx responses are only recognised by their message.
+	case status >= 400 && (strings.Contains(lc, "quota") ||
+		strings.Contains(lc, "storage limit") ||
+		strings.Contains(lc, "data limit")):
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// CheckResponse returns nil for 2xx responses and an *Error otherwise. The
+// body is consumed in the error case; callers still own closing it.
+func CheckResponse(res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+	return DecodeError(res)
+}
+
+// DecodeError builds an *Error from res, understanding the JSON shapes the
+// Lighthouse API and its IPFS-compatible upload node return, and falling back
+// to the plain-text body.
+func DecodeError(res *http.Response) *Error {
+	b, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+	e := &Error{Status: res.StatusCode, Body: b}
+	e.Code, e.Message = parseErrorBody(b)
+	if e.Message == "" {
+		e.Message = http.StatusText(res.StatusCode)
+	}
+	return e
+}
+
+func parseErrorBody(b []byte) (code, msg string) {
+	trimmed := strings.TrimSpace(string(b))
+	if trimmed == "" {
+		return "", ""
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(trimmed), &m); err != nil {
+		return "", trimmed
+	}
+
+	// {"error": "..."} or {"error": {"code": ..., "message": ...}}
+	if raw, ok := lookup(m, "error"); ok {
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			msg = s
+		} else {
+			var inner map[string]json.RawMessage
+			if json.Unmarshal(raw, &inner) == nil {
+				code, msg = parseFields(inner)
+			}
+		}
+	}
+	if msg == "" || code == "" {
+		c, mm := parseFields(m)
+		if code == "" {
+			code = c
+		}
+		if msg == "" {
+			msg = mm
+		}
+	}
+	if msg == "" {
+		msg = trimmed
+	}
+	return code, msg
+}
+
+func parseFields(m map[string]json.RawMessage) (code, msg string) {
+	for _, k := range []string{"message", "msg", "details"} {
+		if raw, ok := lookup(m, k); ok {
+			if s := rawString(raw); s != "" {
+				msg = s
+				break
+			}
+		}
+	}
+	for _, k := range []string{"code", "type"} {
+		if raw, ok := lookup(m, k); ok {
+			if s := rawString(raw); s != "" && s != "0" && s != "error" {
+				code = s
+				break
+			}
+		}
+	}
+	return code, msg
+}
+
+// lookup matches keys case-insensitively ("Message" from the IPFS API,
+// "message" from the Lighthouse API).
+func lookup(m map[string]json.RawMessage, key string) (json.RawMessage, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func rawString(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var n json.Number
+	if json.Unmarshal(raw, &n) == nil {
+		if _, err := strconv.ParseFloat(string(n), 64); err == nil {
+			return string(n)
+		}
+	}
+	return ""
+}
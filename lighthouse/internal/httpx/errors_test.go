@@ -0,0 +1,79 @@
+package httpx
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestErrorCategory(t *testing.T) {
+	for _, tc := range []struct {
+		status int
+		msg    string
+		want   error
+	}{
+		{http.StatusPaymentRequired, "", ErrQuotaExceeded},
+		{http.StatusRequestEntityTooLarge, "data limit exceeded", ErrQuotaExceeded},
+		{http.StatusRequestEntityTooLarge, "file too large", nil},
+		{http.StatusBadRequest, "storage limit reached", ErrQuotaExceeded},
+		{http.StatusConflict, "Quota exceeded", ErrQuotaExceeded},
+		// Status codes with their own category win over the message.
+		{http.StatusUnauthorized, "quota service: invalid key", ErrUnauthorized},
+		{http.StatusForbidden, "quota exceeded", ErrUnauthorized},
+		{http.StatusNotFound, "no quota record", ErrNotFound},
+		{http.StatusTooManyRequests, "request quota exhausted", ErrRateLimited},
+		{http.StatusInternalServerError, "quota lookup failed", ErrServerError},
+		{http.StatusBadRequest, "bad cid", nil},
+		{http.StatusMultipleChoices, "quota", nil},
+	} {
+		err := &Error{Status: tc.status, Message: tc.msg}
+		if got := errors.Unwrap(err); got != tc.want {
+			t.Errorf("%d %q: category %v, want %v", tc.status, tc.msg, got, tc.want)
+		}
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	for _, tc := range []struct {
+		status    int
+		body      string
+		code, msg string
+		want      error
+	}{
+		// Lighthouse API shapes.
+		{http.StatusNotFound, `{"error":"file not found"}`, "", "file not found", ErrNotFound},
+		{http.StatusBadRequest, `{"error":{"code":"LIMIT","message":"Storage limit reached"}}`, "LIMIT", "Storage limit reached", ErrQuotaExceeded},
+		{http.StatusBadRequest, `{"error":{"code":"E1"},"message":"outer"}`, "E1", "outer", nil},
+		{http.StatusConflict, `{"message":"Data limit exceeded","code":409}`, "409", "Data limit exceeded", ErrQuotaExceeded},
+		{http.StatusBadRequest, `{"msg":"bad cid"}`, "", "bad cid", nil},
+		// The upload node's kubo shape; Code 0 and Type "error" carry nothing.
+		{http.StatusInternalServerError, `{"Message":"invalid path \"x\"","Code":0,"Type":"error"}`, "", `invalid path "x"`, ErrServerError},
+		{http.StatusBadRequest, `{"Message":"quota exceeded","Code":1,"Type":"error"}`, "1", "quota exceeded", ErrQuotaExceeded},
+		// Anything else is kept as text.
+		{http.StatusBadRequest, "upload quota exceeded\n", "", "upload quota exceeded", ErrQuotaExceeded},
+		{http.StatusRequestEntityTooLarge, "file too large", "", "file too large", nil},
+		{http.StatusBadRequest, `{"status":"fail"}`, "", `{"status":"fail"}`, nil},
+		{http.StatusBadRequest, `["quota"]`, "", `["quota"]`, ErrQuotaExceeded},
+		{http.StatusBadGateway, "", "", "Bad Gateway", ErrServerError},
+	} {
+		res := &http.Response{StatusCode: tc.status, Body: io.NopCloser(strings.NewReader(tc.body))}
+		e := DecodeError(res)
+		if e.Code != tc.code || e.Message != tc.msg {
+			t.Errorf("%d %s: code %q msg %q, want %q %q", tc.status, tc.body, e.Code, e.Message, tc.code, tc.msg)
+		}
+		if got := errors.Unwrap(e); got != tc.want {
+			t.Errorf("%d %s: category %v, want %v", tc.status, tc.body, got, tc.want)
+		}
+		if string(e.Body) != tc.body {
+			t.Errorf("%d %s: Body = %q", tc.status, tc.body, e.Body)
+		}
+	}
+
+	big := strings.Repeat("x", maxErrorBody+100)
+	e := DecodeError(&http.Response{StatusCode: http.StatusBadRequest, Body: io.NopCloser(strings.NewReader(big))})
+	if len(e.Body) != maxErrorBody {
+		t.Errorf("Body holds %d bytes, want it capped at %d", len(e.Body), maxErrorBody)
+	}
+}
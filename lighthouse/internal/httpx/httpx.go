@@ -4,25 +4,57 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
+	"log/slog"
 	"net/http"
+	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/credentials"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
 )
 
 type Options struct {
 	UserAgent string
-	APIKey    string
+	// Credentials supplies the API key for every request attempt.
+	Credentials credentials.Provider
+	Retry       RetryPolicy
+	// Limiters throttles requests by URL host (e.g. "api.lighthouse.storage").
+	Limiters map[string]*Limiter
+	// Middleware wraps every round trip; the first entry is outermost.
+	Middleware []Middleware
+	// Tracer and Meter receive spans and measurements; nil means no-op.
+	Tracer telemetry.Tracer
+	Meter  telemetry.Meter
+	// Logger receives debug-level request logs; nil discards them.
+	Logger *slog.Logger
 }
 
 type Client struct {
 	inner *http.Client
 	opt   Options
+	rt    RoundTripFunc
+
+	tracer telemetry.Tracer
+	inst   instruments
+	log    *slog.Logger
 }
 
 func New(h *http.Client, opt Options) *Client {
-	return &Client{
+	c := &Client{
 		inner: h,
 		opt:   opt}
+	c.rt = chain(c.inner.Do, opt.Middleware)
+	c.tracer = opt.Tracer
+	if c.tracer == nil {
+		c.tracer = telemetry.NoopTracer{}
+	}
+	c.inst = newInstruments(opt.Meter)
+	c.log = opt.Logger
+	if c.log == nil {
+		c.log = slog.New(discardHandler{})
+	}
+	return c
 }
 
 // executes a prepared *http.Request (used for streaming/multipart).
@@ -30,35 +62,88 @@ func (c *Client) Inject(req *http.Request) (*http.Response, error) {
 	if c.opt.UserAgent != "" && req.Header.Get("User-Agent") == "" {
 		req.Header.Set("User-Agent", c.opt.UserAgent)
 	}
-	if c.opt.APIKey != "" && req.Header.Get("Authorization") == "" {
-		req.Header.Set("Authorization", "Bearer "+c.opt.APIKey)
+	if c.opt.Credentials != nil && req.Header.Get("Authorization") == "" {
+		key, err := c.opt.Credentials.APIKey(req.Context())
+		switch {
+		case err == nil:
+			req.Header.Set("Authorization", "Bearer "+key)
+		case !errors.Is(err, credentials.ErrNoCredentials):
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+	if err := c.opt.Limiters[req.URL.Host].Wait(req.Context()); err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	start := time.Now()
+	res, err := c.rt(req)
+	redactError(err, req.URL)
+	attrs := []any{
+		slog.String("method", req.Method),
+		slog.String("url", RedactURL(req.URL)),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if res != nil {
+		opFrom(req.Context()).observe(res.StatusCode)
+		attrs = append(attrs, slog.Int("status", res.StatusCode))
 	}
-	return c.inner.Do(req)
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	attrs = append(attrs, slog.Any("headers", redactedHeaders(req.Header)))
+	c.log.DebugContext(req.Context(), "lighthouse http request", attrs...)
+	return res, err
 }
 
 // JSON sends optional JSON body and decodes JSON response into out.
+// Idempotent methods are retried according to the client's RetryPolicy.
 func (c *Client) WriteJSON(ctx context.Context, method, url string, in any, out any) (*http.Response, error) {
-	var body io.Reader
+	return c.writeJSON(ctx, method, url, in, out, idempotent(method))
+}
+
+// WriteJSONOnce is WriteJSON without retries, for endpoints that change
+// server state even though their method is idempotent.
+func (c *Client) WriteJSONOnce(ctx context.Context, method, url string, in any, out any) (*http.Response, error) {
+	return c.writeJSON(ctx, method, url, in, out, false)
+}
+
+func (c *Client) writeJSON(ctx context.Context, method, url string, in any, out any, retry bool) (*http.Response, error) {
+	var payload []byte
 	if in != nil {
 		b, err := json.Marshal(in)
 		if err != nil {
 			return nil, err
 		}
-		body = bytes.NewReader(b)
-	}
-	req, err := http.NewRequestWithContext(ctx, method, url, body)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	if c.opt.UserAgent != "" {
-		req.Header.Set("User-Agent", c.opt.UserAgent)
+		payload = b
 	}
-	if c.opt.APIKey != "" {
-		req.Header.Set("Authorization", "Bearer "+c.opt.APIKey)
+	build := func(int) (*http.Request, error) {
+		var body io.Reader
+		if payload != nil {
+			body = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, body)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
 	}
 
-	res, err := c.inner.Do(req)
+	var res *http.Response
+	var err error
+	if retry {
+		res, err = c.Retry(ctx, build)
+	} else {
+		var req *http.Request
+		if req, err = build(0); err == nil {
+			res, err = c.Inject(req)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -66,9 +151,8 @@ func (c *Client) WriteJSON(ctx context.Context, method, url string, in any, out
 		io.Copy(io.Discard, res.Body)
 		res.Body.Close()
 	}()
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		b, _ := io.ReadAll(res.Body)
-		return res, fmt.Errorf("http %d: %s", res.StatusCode, string(b))
+	if err := CheckResponse(res); err != nil {
+		return res, err
 	}
 	if out != nil {
 		return res, json.NewDecoder(res.Body).Decode(out)
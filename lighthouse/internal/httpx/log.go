@@ -0,0 +1,86 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+// Logger returns the client's logger; it is never nil.
+func (c *Client) Logger() *slog.Logger { return c.log }
+
+// discardHandler drops every record (slog.DiscardHandler needs go1.24).
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return d }
+func (d discardHandler) WithGroup(string) slog.Handler           { return d }
+
+// sensitiveParam reports whether a query parameter may carry a credential.
+func sensitiveParam(name string) bool {
+	n := strings.ToLower(name)
+	for _, s := range []string{"key", "token", "secret", "signature", "password", "auth"} {
+		if strings.Contains(n, s) && n != "keyname" && n != "lastkey" {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactURL strips userinfo and masks credential-looking query parameters.
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	cp := *u
+	if cp.User != nil {
+		cp.User = url.User(redacted)
+	}
+	if cp.RawQuery != "" {
+		q := cp.Query()
+		for k := range q {
+			if sensitiveParam(k) {
+				q[k] = []string{redacted}
+			}
+		}
+		cp.RawQuery = q.Encode()
+	}
+	return cp.String()
+}
+
+// redactError masks the URL quoted by a transport error, which would
+// otherwise carry credential query parameters into logs and callers' errors.
+func redactError(err error, u *url.URL) {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		ue.URL = RedactURL(u)
+	}
+}
+
+// redactedHeaders logs a header set with credentials masked.
+type redactedHeaders http.Header
+
+func (h redactedHeaders) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(h))
+	for k, v := range h {
+		val := strings.Join(v, ", ")
+		switch http.CanonicalHeaderKey(k) {
+		case "Authorization", "Proxy-Authorization":
+			if scheme, _, ok := strings.Cut(val, " "); ok {
+				val = scheme + " " + redacted
+			} else {
+				val = redacted
+			}
+		case "Cookie", "Set-Cookie":
+			val = redacted
+		}
+		attrs = append(attrs, slog.String(k, val))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,110 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/credentials"
+)
+
+const secret = "s3cr3t-value"
+
+// debugClient returns a client whose debug log is written to the buffer.
+func debugClient(p RetryPolicy) (*Client, *bytes.Buffer) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	return New(http.DefaultClient, Options{
+		Credentials: credentials.Static(secret),
+		Retry:       p,
+		Logger:      log,
+	}), &buf
+}
+
+func TestRedactURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://user:" + secret + "@host/p":                     "https://REDACTED@host/p",
+		"https://host/p?apiKey=" + secret + "&keyName=site":      "https://host/p?apiKey=REDACTED&keyName=site",
+		"https://host/p?access_token=" + secret + "&lastKey=abc": "https://host/p?access_token=REDACTED&lastKey=abc",
+		"https://host/p?signedMessage=x&signature=" + secret:     "https://host/p?signature=REDACTED&signedMessage=x",
+		"https://host/p?cid=bafy":                                "https://host/p?cid=bafy",
+	} {
+		u, _ := url.Parse(in)
+		if got := RedactURL(u); got != want {
+			t.Errorf("RedactURL(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestRedactedHeaders(t *testing.T) {
+	h := http.Header{
+		"Authorization":       {"Bearer " + secret},
+		"Proxy-Authorization": {secret},
+		"Cookie":              {"session=" + secret},
+		"Set-Cookie":          {"session=" + secret + "; HttpOnly"},
+		"Accept":              {"application/json"},
+	}
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("req", slog.Any("headers", redactedHeaders(h)))
+	out := buf.String()
+	if strings.Contains(out, secret) {
+		t.Errorf("headers logged a secret: %s", out)
+	}
+	for _, want := range []string{"Authorization=\"Bearer REDACTED\"", "Accept=application/json"} {
+		if !strings.Contains(out, "headers."+want) {
+			t.Errorf("log %s lacks headers.%s", out, want)
+		}
+	}
+}
+
+// Nothing a request carries as a credential reaches the debug log, including
+// the retry and failure messages.
+func TestDebugLogRedactsCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: secret})
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	c, buf := debugClient(RetryPolicy{MaxAttempts: 2})
+
+	u := srv.URL + "/api?apiKey=" + secret + "&cid=bafy"
+	res, err := c.Retry(context.Background(), func(int) (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err == nil {
+			req.Header.Set("Cookie", "session="+secret)
+		}
+		return req, err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	// A transport error quotes the URL; it must be redacted too.
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	_, err = c.Retry(context.Background(), func(int) (*http.Request, error) {
+		return http.NewRequest(http.MethodGet, closed.URL+"/api?token="+secret, nil)
+	})
+	if err == nil {
+		t.Fatal("request to a closed server succeeded")
+	}
+	if strings.Contains(err.Error(), secret) {
+		t.Errorf("returned error contains the secret: %v", err)
+	}
+
+	out := buf.String()
+	if strings.Contains(out, secret) {
+		t.Errorf("debug log contains the secret:\n%s", out)
+	}
+	for _, want := range []string{"lighthouse http request", "lighthouse retrying request", "apiKey=REDACTED", "Bearer REDACTED"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("debug log lacks %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package httpx
+
+import "net/http"
+
+// RoundTripFunc sends a single HTTP request.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// Middleware wraps the round trip of every request the client sends,
+// including each retry attempt.
+type Middleware interface {
+	Wrap(next RoundTripFunc) RoundTripFunc
+}
+
+// MiddlewareFunc adapts an ordinary function to Middleware.
+type MiddlewareFunc func(next RoundTripFunc) RoundTripFunc
+
+func (f MiddlewareFunc) Wrap(next RoundTripFunc) RoundTripFunc { return f(next) }
+
+// chain wraps base so that mws[0] is the outermost middleware.
+func chain(base RoundTripFunc, mws []Middleware) RoundTripFunc {
+	rt := base
+	for i := len(mws) - 1; i >= 0; i-- {
+		if mws[i] != nil {
+			rt = mws[i].Wrap(rt)
+		}
+	}
+	return rt
+}
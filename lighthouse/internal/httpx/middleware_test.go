@@ -0,0 +1,84 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/credentials"
+)
+
+// tag records entering and leaving a middleware in trace.
+func tag(name string, trace *[]string) Middleware {
+	return MiddlewareFunc(func(next RoundTripFunc) RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			*trace = append(*trace, name+">")
+			res, err := next(r)
+			*trace = append(*trace, "<"+name)
+			return res, err
+		}
+	})
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var trace []string
+	base := func(*http.Request) (*http.Response, error) {
+		trace = append(trace, "send")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}
+	rt := chain(base, []Middleware{tag("a", &trace), nil, tag("b", &trace), tag("c", &trace)})
+	req, _ := http.NewRequest(http.MethodGet, "http://example.invalid/", nil)
+	if _, err := rt(req); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a>", "b>", "c>", "send", "<c", "<b", "<a"}
+	if !slices.Equal(trace, want) {
+		t.Errorf("call order = %q, want %q", trace, want)
+	}
+}
+
+// Every retry attempt passes through the middleware, with the default
+// headers already set.
+func TestMiddlewareSeesEachAttempt(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls++; calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var seen []int
+	mw := MiddlewareFunc(func(next RoundTripFunc) RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			if r.Header.Get("Authorization") != "Bearer key" || r.Header.Get("User-Agent") != "test-agent" {
+				t.Errorf("middleware saw headers %v before defaults were set", r.Header)
+			}
+			res, err := next(r)
+			if res != nil {
+				seen = append(seen, res.StatusCode)
+			}
+			return res, err
+		}
+	})
+	c := New(http.DefaultClient, Options{
+		UserAgent:   "test-agent",
+		Credentials: credentials.Static("key"),
+		Retry:       RetryPolicy{MaxAttempts: 3},
+		Middleware:  []Middleware{mw},
+	})
+	res, err := c.Retry(context.Background(), func(int) (*http.Request, error) {
+		return http.NewRequest(http.MethodGet, srv.URL, nil)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if want := []int{503, 503, 200}; !slices.Equal(seen, want) {
+		t.Errorf("middleware saw %v, want %v", seen, want)
+	}
+}
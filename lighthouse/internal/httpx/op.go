@@ -0,0 +1,130 @@
+package httpx
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
+)
+
+// instruments holds the metric instruments created once per client.
+type instruments struct {
+	duration telemetry.Float64Histogram
+	uploaded telemetry.Int64Counter
+	retries  telemetry.Int64Counter
+}
+
+func newInstruments(m telemetry.Meter) instruments {
+	if m == nil {
+		m = telemetry.NoopMeter{}
+	}
+	return instruments{
+		duration: m.Float64Histogram(telemetry.MetricOperationDuration, "s", "Duration of Lighthouse SDK operations."),
+		uploaded: m.Int64Counter(telemetry.MetricUploadedBytes, "By", "Bytes uploaded to Lighthouse."),
+		retries:  m.Int64Counter(telemetry.MetricRetries, "{retry}", "HTTP requests retried by the SDK."),
+	}
+}
+
+// Op is one service-level call (e.g. "files.List"). It owns a span, and
+// collects the HTTP status and retry count of the requests made under it.
+type Op struct {
+	c     *Client
+	ctx   context.Context
+	name  string
+	span  telemetry.Span
+	start time.Time
+
+	mu      sync.Mutex
+	status  int
+	retries int
+}
+
+type opKey struct{}
+
+// StartOp begins an operation; the returned context must be used for the
+// requests that belong to it, and End must be called exactly once.
+func (c *Client) StartOp(ctx context.Context, name string, attrs ...telemetry.Attr) (context.Context, *Op) {
+	ctx, span := c.tracer.Start(ctx, name, attrs...)
+	op := &Op{c: c, name: name, span: span, start: time.Now()}
+	ctx = context.WithValue(ctx, opKey{}, op)
+	op.ctx = ctx
+	return ctx, op
+}
+
+func opFrom(ctx context.Context) *Op {
+	op, _ := ctx.Value(opKey{}).(*Op)
+	return op
+}
+
+func (o *Op) SetAttributes(attrs ...telemetry.Attr) {
+	if o != nil {
+		o.span.SetAttributes(attrs...)
+	}
+}
+
+// AddUploaded records n payload bytes as uploaded.
+func (o *Op) AddUploaded(n int64) {
+	if o == nil || n <= 0 {
+		return
+	}
+	o.c.inst.uploaded.Add(o.ctx, n, telemetry.String(telemetry.AttrOperation, o.name))
+}
+
+func (o *Op) observe(status int) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	o.status = status
+	o.mu.Unlock()
+}
+
+func (o *Op) retried() {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	o.retries++
+	o.mu.Unlock()
+	o.c.inst.retries.Add(o.ctx, 1, telemetry.String(telemetry.AttrOperation, o.name))
+}
+
+// End finishes the span and records the operation's duration.
+func (o *Op) End(err error) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	status, retries := o.status, o.retries
+	o.mu.Unlock()
+
+	attrs := []telemetry.Attr{telemetry.Int(telemetry.AttrRetryCount, retries)}
+	if status != 0 {
+		attrs = append(attrs, telemetry.Int(telemetry.AttrHTTPStatus, status))
+	}
+	o.span.SetAttributes(attrs...)
+	if err != nil {
+		o.span.RecordError(err)
+	}
+	o.span.End()
+
+	elapsed := time.Since(o.start)
+	o.c.inst.duration.Record(o.ctx, elapsed.Seconds(),
+		telemetry.String(telemetry.AttrOperation, o.name),
+		telemetry.Bool("error", err != nil))
+
+	logAttrs := []any{
+		slog.String("operation", o.name),
+		slog.Duration("duration", elapsed),
+		slog.Int("retries", retries),
+	}
+	if status != 0 {
+		logAttrs = append(logAttrs, slog.Int("status", status))
+	}
+	if err != nil {
+		logAttrs = append(logAttrs, slog.Any("error", err))
+	}
+	o.c.log.DebugContext(o.ctx, "lighthouse operation finished", logAttrs...)
+}
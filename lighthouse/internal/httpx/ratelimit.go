@@ -0,0 +1,85 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimit configures a token bucket: RPS tokens are added per second up to
+// Burst. A zero RPS means unlimited.
+type RateLimit struct {
+	RPS   float64
+	Burst int
+}
+
+// Limiter is a context-aware token bucket safe for concurrent use. Waiters
+// reserve tokens in arrival order, so concurrent callers queue rather than
+// stampede when the bucket refills.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter returns a limiter for l, or nil if l is unlimited.
+func NewLimiter(l RateLimit) *Limiter {
+	if l.RPS <= 0 {
+		return nil
+	}
+	burst := float64(l.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{rate: l.RPS, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// Wait blocks until a token is available or ctx is done. A nil limiter never blocks.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	if dl, ok := ctx.Deadline(); ok && time.Until(dl) < wait {
+		l.cancel()
+		return fmt.Errorf("rate limit wait of %s exceeds context deadline: %w", wait, context.DeadlineExceeded)
+	}
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// cancel returns an unused reservation to the bucket. The bucket may have
+// refilled since the reservation, so it is clamped to burst.
+func (l *Limiter) cancel() {
+	l.mu.Lock()
+	l.tokens = min(l.tokens+1, l.burst)
+	l.mu.Unlock()
+}
@@ -0,0 +1,102 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A cancelled wait returns its token, but never beyond the burst size.
+func TestLimiterCancelClampsToBurst(t *testing.T) {
+	l := NewLimiter(RateLimit{RPS: 1, Burst: 2})
+	for range 3 {
+		l.cancel()
+	}
+	if l.tokens != l.burst {
+		t.Errorf("tokens = %v after cancel on a full bucket, want %v", l.tokens, l.burst)
+	}
+
+	ctx := context.Background()
+	for range 2 {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(short); err == nil {
+		t.Fatal("Wait on an empty bucket succeeded before the deadline")
+	}
+	if l.tokens > 0.1 || l.tokens < -0.1 {
+		t.Errorf("tokens = %v after a cancelled wait, want about 0", l.tokens)
+	}
+}
+
+// Concurrent waiters are let through one at a time at the configured rate
+// once the burst is spent.
+func TestLimiterQueues(t *testing.T) {
+	const n, rps = 6, 50
+	interval := time.Second / rps
+	l := NewLimiter(RateLimit{RPS: rps, Burst: 2})
+	start := time.Now()
+	var (
+		mu   sync.Mutex
+		done []time.Duration
+		wg   sync.WaitGroup
+	)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.Wait(context.Background()); err != nil {
+				t.Error(err)
+			}
+			mu.Lock()
+			done = append(done, time.Since(start))
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	slices.Sort(done)
+	// The first two use the burst; each later one waits another interval.
+	for i, d := range done {
+		want := time.Duration(max(i-1, 0)) * interval
+		if d < want*9/10 {
+			t.Errorf("waiter %d done after %v, want at least %v", i, d, want)
+		}
+	}
+	if done[1] > interval/2 {
+		t.Errorf("second waiter done after %v, want the burst to pass at once", done[1])
+	}
+}
+
+func TestLimiterWaitCancelled(t *testing.T) {
+	l := NewLimiter(RateLimit{RPS: 1})
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	if err := l.Wait(ctx); err != ctx.Err() || !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want ctx.Err()", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("cancelled wait returned after %v", d)
+	}
+
+	// A deadline that comes before the token fails without waiting for it.
+	ctx, cancel = context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Errorf("wait past the deadline returned after %v, want at once", d)
+	}
+}
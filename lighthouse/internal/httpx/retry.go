@@ -0,0 +1,221 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. MaxAttempts counts
+// the first attempt, so a value of 1 (or less) disables retries.
+type RetryPolicy struct {
+	MaxAttempts int
+	// Backoff before attempt n (n>=1) is InitialBackoff*Multiplier^(n-1),
+	// capped at MaxBackoff. MaxBackoff also caps Retry-After waits.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter spreads each delay uniformly over ±Jitter of its value (0..1).
+	Jitter float64
+	// RetryableStatus lists response codes worth retrying. Nil uses
+	// DefaultRetryableStatus.
+	RetryableStatus []int
+	// ShouldRetry, if set, replaces the built-in status/error classification.
+	ShouldRetry func(res *http.Response, err error) bool
+}
+
+// DefaultRetryableStatus is the set of codes retried when RetryPolicy.RetryableStatus is nil.
+var DefaultRetryableStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryPolicy makes up to 3 attempts with 500ms, 1s backoff (±20%).
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// NoRetry disables retries.
+func NoRetry() RetryPolicy { return RetryPolicy{MaxAttempts: 1} }
+
+// Backoff returns the delay before the given retry (1 = first retry).
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	if retry < 1 || p.InitialBackoff <= 0 {
+		return 0
+	}
+	mult := p.Multiplier
+	if mult < 1 {
+		mult = 1
+	}
+	d := float64(p.InitialBackoff) * math.Pow(mult, float64(retry-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		j := math.Min(p.Jitter, 1)
+		d *= 1 - j + rand.Float64()*2*j
+	}
+	return time.Duration(d)
+}
+
+// Retryable reports whether a request that produced res/err should be retried.
+func (p RetryPolicy) Retryable(res *http.Response, err error) bool {
+	if p.ShouldRetry != nil {
+		return p.ShouldRetry(res, err)
+	}
+	if err != nil {
+		return retryableError(err)
+	}
+	if res == nil {
+		return false
+	}
+	codes := p.RetryableStatus
+	if codes == nil {
+		codes = DefaultRetryableStatus
+	}
+	for _, c := range codes {
+		if res.StatusCode == c {
+			return true
+		}
+	}
+	return false
+}
+
+func retryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) {
+		return true
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	var oe *net.OpError
+	return errors.As(err, &oe)
+}
+
+// retryAfter parses a Retry-After header in either delta-seconds or
+// HTTP-date form. Negative values and past dates yield 0.
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	if res == nil {
+		return 0, false
+	}
+	v := res.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+func retryReason(res *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return res.Status
+}
+
+// idempotent reports whether method can be safely replayed.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// Retry executes the request produced by build, rebuilding and resending it
+// according to the client's RetryPolicy. build is called once per attempt
+// (0-based) and must return a fresh request with an unread body. The caller
+// owns the returned response, which may be a non-2xx response if retries
+// were exhausted.
+func (c *Client) Retry(ctx context.Context, build func(attempt int) (*http.Request, error)) (*http.Response, error) {
+	p := c.opt.Retry
+	max := p.MaxAttempts
+	if max < 1 {
+		max = 1
+	}
+	for attempt := 0; ; attempt++ {
+		req, err := build(attempt)
+		if err != nil {
+			return nil, err
+		}
+		res, err := c.Inject(req)
+		if !p.Retryable(res, err) || ctx.Err() != nil {
+			return res, err
+		}
+		if attempt+1 >= max {
+			if max > 1 {
+				c.log.DebugContext(ctx, "lighthouse retries exhausted",
+					slog.Int("attempts", attempt+1),
+					slog.String("reason", retryReason(res, err)))
+			}
+			return res, err
+		}
+
+		wait := p.Backoff(attempt + 1)
+		if d, ok := retryAfter(res); ok {
+			wait = d
+			if p.MaxBackoff > 0 {
+				wait = min(wait, p.MaxBackoff)
+			}
+		}
+		reason := retryReason(res, err)
+		if res != nil {
+			io.Copy(io.Discard, io.LimitReader(res.Body, maxErrorBody))
+			res.Body.Close()
+		}
+		opFrom(ctx).retried()
+		c.log.DebugContext(ctx, "lighthouse retrying request",
+			slog.Int("attempt", attempt+1),
+			slog.Int("max_attempts", max),
+			slog.Duration("wait", wait),
+			slog.String("reason", reason))
+
+		if wait <= 0 {
+			continue
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// RetryPolicy returns the policy used by Retry, for callers that resume
+// streams themselves.
+func (c *Client) RetryPolicy() RetryPolicy { return c.opt.Retry }
@@ -0,0 +1,61 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// retryServer answers 503 with the given Retry-After once, then 200.
+func retryServer(t *testing.T, retryAfter string) *httptest.Server {
+	t.Helper()
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls++; calls == 1 {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func retryGet(t *testing.T, p RetryPolicy, url string) time.Duration {
+	t.Helper()
+	c := New(http.DefaultClient, Options{Retry: p})
+	start := time.Now()
+	res, err := c.Retry(context.Background(), func(int) (*http.Request, error) {
+		return http.NewRequest(http.MethodGet, url, nil)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200 after a retry", res.StatusCode)
+	}
+	return time.Since(start)
+}
+
+func TestRetryAfterCappedByMaxBackoff(t *testing.T) {
+	srv := retryServer(t, "3600")
+	p := RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: 20 * time.Millisecond}
+	if d := retryGet(t, p, srv.URL); d > time.Second {
+		t.Errorf("retry waited %s despite MaxBackoff of 20ms", d)
+	}
+}
+
+func TestRetryAfterZeroRetriesImmediately(t *testing.T) {
+	for _, v := range []string{"0", "-5", "Mon, 02 Jan 2006 15:04:05 GMT"} {
+		srv := retryServer(t, v)
+		// The backoff would wait an hour; Retry-After must override it.
+		p := RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+		if d := retryGet(t, p, srv.URL); d > time.Second {
+			t.Errorf("Retry-After %q: retry waited %s", v, d)
+		}
+	}
+}
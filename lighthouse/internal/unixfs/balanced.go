@@ -0,0 +1,49 @@
+package unixfs
+
+// DefaultLinksPerBlock is the fan-out kubo uses for balanced file DAGs.
+const DefaultLinksPerBlock = 174
+
+// Child is an already-built subtree of a UnixFS file.
+type Child struct {
+	CID      CID
+	FileSize uint64 // bytes of file content below this node
+	Tsize    uint64 // cumulative encoded size of the subtree
+}
+
+// Block is an encoded block and its CID.
+type Block struct {
+	CID  CID
+	Data []byte
+}
+
+// Balanced links children, in order, into a balanced UnixFS file tree with at
+// most width links per node. It returns the root and every internal node it
+// created, children before parents. A single child is returned as the root.
+func Balanced(children []Child, width int) (Child, []Block) {
+	if width < 2 {
+		width = DefaultLinksPerBlock
+	}
+	if len(children) == 0 {
+		blk := FileNode(nil)
+		return Child{CID: blk.CID, Tsize: uint64(len(blk.Data))}, []Block{blk}
+	}
+
+	var blocks []Block
+	level := children
+	for len(level) > 1 {
+		var next []Child
+		for i := 0; i < len(level); i += width {
+			group := level[i:min(i+width, len(level))]
+			blk := FileNode(group)
+			c := Child{CID: blk.CID, Tsize: uint64(len(blk.Data))}
+			for _, g := range group {
+				c.FileSize += g.FileSize
+				c.Tsize += g.Tsize
+			}
+			blocks = append(blocks, blk)
+			next = append(next, c)
+		}
+		level = next
+	}
+	return level[0], blocks
+}
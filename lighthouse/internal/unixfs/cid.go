@@ -0,0 +1,200 @@
+// Package unixfs encodes the minimal subset of CIDs, dag-pb and UnixFS
+// needed to build and verify file DAGs without external dependencies.
+package unixfs
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Multicodec and multihash codes.
+const (
+	CodecRaw    = 0x55
+	CodecDagPB  = 0x70
+	HashSHA2256 = 0x12
+)
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// CID is a binary CID (version, codec and multihash).
+type CID struct {
+	Version int
+	Codec   uint64
+	Hash    []byte // full multihash: code, length, digest
+}
+
+// Sum returns the CIDv1 of data hashed with sha2-256.
+func Sum(codec uint64, data []byte) CID {
+	d := sha256.Sum256(data)
+	mh := append([]byte{HashSHA2256, sha256.Size}, d[:]...)
+	return CID{Version: 1, Codec: codec, Hash: mh}
+}
+
+// Bytes returns the binary form used inside dag-pb links.
+func (c CID) Bytes() []byte {
+	if c.Version == 0 {
+		return append([]byte(nil), c.Hash...)
+	}
+	b := binary.AppendUvarint(nil, 1)
+	b = binary.AppendUvarint(b, c.Codec)
+	return append(b, c.Hash...)
+}
+
+// String returns base32 (CIDv1) or base58btc (CIDv0).
+func (c CID) String() string {
+	if c.Version == 0 {
+		return base58Encode(c.Hash)
+	}
+	return "b" + strings.ToLower(b32.EncodeToString(c.Bytes()))
+}
+
+func (c CID) Equal(o CID) bool {
+	return c.Version == o.Version && c.Codec == o.Codec && string(c.Hash) == string(o.Hash)
+}
+
+// Digest returns the multihash code and digest.
+func (c CID) Digest() (uint64, []byte, error) {
+	code, n := binary.Uvarint(c.Hash)
+	if n <= 0 {
+		return 0, nil, errors.New("invalid multihash")
+	}
+	l, m := binary.Uvarint(c.Hash[n:])
+	if m <= 0 || uint64(len(c.Hash)-n-m) != l {
+		return 0, nil, errors.New("invalid multihash length")
+	}
+	return code, c.Hash[n+m:], nil
+}
+
+// Parse decodes a CIDv0 ("Qm...") or a base32 CIDv1 ("b...").
+func Parse(s string) (CID, error) {
+	if len(s) == 46 && strings.HasPrefix(s, "Qm") {
+		mh, err := base58Decode(s)
+		if err != nil {
+			return CID{}, err
+		}
+		return CID{Version: 0, Codec: CodecDagPB, Hash: mh}, nil
+	}
+	if len(s) < 2 {
+		return CID{}, fmt.Errorf("invalid cid %q", s)
+	}
+	var b []byte
+	var err error
+	switch s[0] {
+	case 'b':
+		b, err = b32.DecodeString(strings.ToUpper(s[1:]))
+	case 'B':
+		b, err = b32.DecodeString(s[1:])
+	case 'z':
+		b, err = base58Decode(s[1:])
+	default:
+		return CID{}, fmt.Errorf("unsupported multibase in cid %q", s)
+	}
+	if err != nil {
+		return CID{}, fmt.Errorf("invalid cid %q: %w", s, err)
+	}
+	return Decode(b)
+}
+
+// Decode parses a binary CID.
+func Decode(b []byte) (CID, error) {
+	c, n, err := DecodePrefix(b)
+	if err != nil {
+		return CID{}, err
+	}
+	if n != len(b) {
+		return CID{}, errors.New("trailing bytes after cid")
+	}
+	return c, nil
+}
+
+// DecodePrefix parses the binary CID at the start of b and reports how many
+// bytes it occupied.
+func DecodePrefix(b []byte) (CID, int, error) {
+	if len(b) >= 34 && b[0] == HashSHA2256 && b[1] == sha256.Size {
+		return CID{Version: 0, Codec: CodecDagPB, Hash: append([]byte(nil), b[:34]...)}, 34, nil
+	}
+	v, n := binary.Uvarint(b)
+	if n <= 0 || v != 1 {
+		return CID{}, 0, errors.New("unsupported cid version")
+	}
+	codec, m := binary.Uvarint(b[n:])
+	if m <= 0 {
+		return CID{}, 0, errors.New("invalid cid codec")
+	}
+	off := n + m
+	_, k := binary.Uvarint(b[off:])
+	if k <= 0 {
+		return CID{}, 0, errors.New("invalid multihash")
+	}
+	l, j := binary.Uvarint(b[off+k:])
+	if j <= 0 || uint64(len(b)-off-k-j) < l {
+		return CID{}, 0, errors.New("invalid multihash length")
+	}
+	end := off + k + j + int(l)
+	return CID{Version: 1, Codec: codec, Hash: append([]byte(nil), b[off:end]...)}, end, nil
+}
+
+// Verify reports whether data hashes to c. Only sha2-256 is supported.
+func (c CID) Verify(data []byte) error {
+	code, digest, err := c.Digest()
+	if err != nil {
+		return err
+	}
+	if code != HashSHA2256 {
+		return fmt.Errorf("unsupported multihash 0x%x", code)
+	}
+	sum := sha256.Sum256(data)
+	if string(sum[:]) != string(digest) {
+		return errors.New("block hash mismatch")
+	}
+	return nil
+}
+
+const b58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func base58Encode(b []byte) string {
+	x := new(big.Int).SetBytes(b)
+	mod := new(big.Int)
+	base := big.NewInt(58)
+	var out []byte
+	for x.Sign() > 0 {
+		x.DivMod(x, base, mod)
+		out = append(out, b58Alphabet[mod.Int64()])
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, '1')
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	x := new(big.Int)
+	base := big.NewInt(58)
+	for _, r := range s {
+		i := strings.IndexRune(b58Alphabet, r)
+		if i < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", r)
+		}
+		x.Mul(x, base)
+		x.Add(x, big.NewInt(int64(i)))
+	}
+	out := x.Bytes()
+	for _, r := range s {
+		if r != '1' {
+			break
+		}
+		out = append([]byte{0}, out...)
+	}
+	return out, nil
+}
@@ -0,0 +1,205 @@
+package unixfs
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// UnixFS data types.
+const (
+	TypeRaw       = 0
+	TypeDirectory = 1
+	TypeFile      = 2
+	TypeMetadata  = 3
+	TypeSymlink   = 4
+	TypeHAMTShard = 5
+)
+
+// Link is a dag-pb link.
+type Link struct {
+	CID   CID
+	Name  string
+	Tsize uint64
+}
+
+// Node is a decoded dag-pb node.
+type Node struct {
+	Links []Link
+	Data  []byte
+}
+
+// Data is a decoded UnixFS Data message.
+type Data struct {
+	Type       int
+	Data       []byte
+	FileSize   uint64
+	BlockSizes []uint64
+	HasSize    bool
+	Fanout     uint64 // HAMT shards only; not encoded
+}
+
+// protobuf wire types
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func appendTag(b []byte, field, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wire))
+}
+
+func appendBytes(b []byte, field int, v []byte) []byte {
+	b = appendTag(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendVarint(b []byte, field int, v uint64) []byte {
+	b = appendTag(b, field, wireVarint)
+	return binary.AppendUvarint(b, v)
+}
+
+// Encode serialises n in canonical dag-pb form (links before data), as
+// produced by go-ipfs/kubo.
+func (n Node) Encode() []byte {
+	var b []byte
+	for _, l := range n.Links {
+		var lb []byte
+		lb = appendBytes(lb, 1, l.CID.Bytes())
+		lb = appendBytes(lb, 2, []byte(l.Name))
+		lb = appendVarint(lb, 3, l.Tsize)
+		b = appendBytes(b, 2, lb)
+	}
+	if n.Data != nil {
+		b = appendBytes(b, 1, n.Data)
+	}
+	return b
+}
+
+// Encode serialises d as a UnixFS Data message.
+func (d Data) Encode() []byte {
+	var b []byte
+	b = appendVarint(b, 1, uint64(d.Type))
+	if len(d.Data) > 0 {
+		b = appendBytes(b, 2, d.Data)
+	}
+	if d.HasSize || d.Type == TypeFile || d.Type == TypeRaw {
+		b = appendVarint(b, 3, d.FileSize)
+	}
+	for _, s := range d.BlockSizes {
+		b = appendVarint(b, 4, s)
+	}
+	return b
+}
+
+type field struct {
+	num   int
+	wire  int
+	value uint64
+	bytes []byte
+}
+
+func readFields(b []byte, fn func(field) error) error {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errors.New("invalid protobuf tag")
+		}
+		b = b[n:]
+		f := field{num: int(key >> 3), wire: int(key & 7)}
+		switch f.wire {
+		case wireVarint:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return errors.New("invalid protobuf varint")
+			}
+			f.value = v
+			b = b[n:]
+		case wireBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return errors.New("invalid protobuf length")
+			}
+			f.bytes = b[n : n+int(l)]
+			b = b[n+int(l):]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", f.wire)
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeNode parses a dag-pb block.
+func DecodeNode(b []byte) (Node, error) {
+	var n Node
+	err := readFields(b, func(f field) error {
+		switch {
+		case f.num == 1 && f.wire == wireBytes:
+			n.Data = append([]byte{}, f.bytes...)
+		case f.num == 2 && f.wire == wireBytes:
+			var l Link
+			err := readFields(f.bytes, func(lf field) error {
+				switch {
+				case lf.num == 1 && lf.wire == wireBytes:
+					c, err := Decode(lf.bytes)
+					if err != nil {
+						return err
+					}
+					l.CID = c
+				case lf.num == 2 && lf.wire == wireBytes:
+					l.Name = string(lf.bytes)
+				case lf.num == 3 && lf.wire == wireVarint:
+					l.Tsize = lf.value
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			n.Links = append(n.Links, l)
+		}
+		return nil
+	})
+	return n, err
+}
+
+// DecodeData parses a UnixFS Data message.
+func DecodeData(b []byte) (Data, error) {
+	var d Data
+	err := readFields(b, func(f field) error {
+		switch {
+		case f.num == 1 && f.wire == wireVarint:
+			d.Type = int(f.value)
+		case f.num == 2 && f.wire == wireBytes:
+			d.Data = append([]byte{}, f.bytes...)
+		case f.num == 3 && f.wire == wireVarint:
+			d.FileSize = f.value
+			d.HasSize = true
+		case f.num == 4 && f.wire == wireVarint:
+			d.BlockSizes = append(d.BlockSizes, f.value)
+		case f.num == 4 && f.wire == wireBytes:
+			// packed encoding
+			return readPacked(f.bytes, &d.BlockSizes)
+		case f.num == 6 && f.wire == wireVarint:
+			d.Fanout = f.value
+		}
+		return nil
+	})
+	return d, err
+}
+
+func readPacked(b []byte, out *[]uint64) error {
+	for len(b) > 0 {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errors.New("invalid packed varint")
+		}
+		*out = append(*out, v)
+		b = b[n:]
+	}
+	return nil
+}
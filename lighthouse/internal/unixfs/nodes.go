@@ -0,0 +1,34 @@
+package unixfs
+
+import "sort"
+
+// FileNode encodes a UnixFS file node linking children.
+func FileNode(children []Child) Block {
+	d := Data{Type: TypeFile}
+	n := Node{}
+	for _, c := range children {
+		d.FileSize += c.FileSize
+		d.BlockSizes = append(d.BlockSizes, c.FileSize)
+		n.Links = append(n.Links, Link{CID: c.CID, Tsize: c.Tsize})
+	}
+	n.Data = d.Encode()
+	enc := n.Encode()
+	return Block{CID: Sum(CodecDagPB, enc), Data: enc}
+}
+
+// DirectoryNode encodes a basic (non-sharded) UnixFS directory. Links are
+// sorted by name as dag-pb requires.
+func DirectoryNode(links []Link) Block {
+	sorted := append([]Link(nil), links...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	n := Node{Links: sorted, Data: Data{Type: TypeDirectory}.Encode()}
+	enc := n.Encode()
+	return Block{CID: Sum(CodecDagPB, enc), Data: enc}
+}
+
+// SymlinkNode encodes a UnixFS symlink pointing at target.
+func SymlinkNode(target string) Block {
+	d := Data{Type: TypeSymlink, Data: []byte(target)}
+	enc := Node{Data: d.Encode()}.Encode()
+	return Block{CID: Sum(CodecDagPB, enc), Data: enc}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/cfg"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/httpx"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
 )
 
 type Service struct {
@@ -18,22 +19,32 @@ func New(h *httpx.Client, c cfg.Config) *Service {
 	return &Service{h: h, cfg: c}
 }
 
-func (s *Service) GenerateKey(ctx context.Context, keyName string) (*schema.IPNSKeyResponse, error) {
+// GenerateKey creates an IPNS key. The endpoint is a GET that creates state,
+// so it is never retried: a retry after a lost response would fail because
+// the key already exists.
+func (s *Service) GenerateKey(ctx context.Context, keyName string) (_ *schema.IPNSKeyResponse, err error) {
+	ctx, op := s.h.StartOp(ctx, "ipns.GenerateKey")
+	defer func() { op.End(err) }()
+
 	u := s.cfg.Hosts.API + "/api/ipns/generate_key?keyName=" + url.QueryEscape(keyName)
 
 	var response schema.IPNSKeyResponse
-	_, err := s.h.WriteJSON(ctx, "GET", u, nil, &response)
+	_, err = s.h.WriteJSONOnce(ctx, "GET", u, nil, &response)
 	if err != nil {
 		return nil, err
 	}
 	return &response, nil
 }
 
-func (s *Service) PublishRecord(ctx context.Context, cid, keyName string) (*schema.IPNSPublishResponse, error) {
+// PublishRecord points keyName at cid. Like GenerateKey it is not retried.
+func (s *Service) PublishRecord(ctx context.Context, cid, keyName string) (_ *schema.IPNSPublishResponse, err error) {
+	ctx, op := s.h.StartOp(ctx, "ipns.PublishRecord", telemetry.String(telemetry.AttrCID, cid))
+	defer func() { op.End(err) }()
+
 	u := s.cfg.Hosts.Upload + "/api/ipns/publish_recored?cid=" + url.QueryEscape(cid) + "&keyName=" + url.QueryEscape(keyName)
 
 	var response schema.IPNSPublishResponse
-	_, err := s.h.WriteJSON(ctx, "GET", u, nil, &response)
+	_, err = s.h.WriteJSONOnce(ctx, "GET", u, nil, &response)
 	if err != nil {
 		return nil, err
 	}
@@ -41,11 +52,14 @@ func (s *Service) PublishRecord(ctx context.Context, cid, keyName string) (*sche
 	return &response, nil
 }
 
-func (s *Service) ListKeys(ctx context.Context) ([]schema.IPNSRecord, error) {
+func (s *Service) ListKeys(ctx context.Context) (_ []schema.IPNSRecord, err error) {
+	ctx, op := s.h.StartOp(ctx, "ipns.ListKeys")
+	defer func() { op.End(err) }()
+
 	u := s.cfg.Hosts.API + "/api/ipns/get_ipns_records"
 
 	var records []schema.IPNSRecord
-	_, err := s.h.WriteJSON(ctx, "GET", u, nil, &records)
+	_, err = s.h.WriteJSON(ctx, "GET", u, nil, &records)
 	if err != nil {
 		return nil, err
 	}
@@ -53,11 +67,14 @@ func (s *Service) ListKeys(ctx context.Context) ([]schema.IPNSRecord, error) {
 	return records, nil
 }
 
-func (s *Service) RemoveKey(ctx context.Context, keyName string) (*schema.IPNSRemoveResponse, error) {
+func (s *Service) RemoveKey(ctx context.Context, keyName string) (_ *schema.IPNSRemoveResponse, err error) {
+	ctx, op := s.h.StartOp(ctx, "ipns.RemoveKey")
+	defer func() { op.End(err) }()
+
 	u := s.cfg.Hosts.API + "/api/ipns/remove_key?keyName=" + url.QueryEscape(keyName)
 
 	var response schema.IPNSRemoveResponse
-	_, err := s.h.WriteJSON(ctx, "DELETE", u, nil, &response)
+	_, err = s.h.WriteJSON(ctx, "DELETE", u, nil, &response)
 	if err != nil {
 		return nil, err
 	}
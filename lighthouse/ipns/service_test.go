@@ -0,0 +1,82 @@
+package ipns_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+)
+
+// badGateway lets requests reach the server but replaces the response for
+// paths with the prefix with a 502, as a proxy losing the reply would.
+type badGateway string
+
+func (p badGateway) RoundTrip(r *http.Request) (*http.Response, error) {
+	res, err := http.DefaultTransport.RoundTrip(r)
+	if err != nil || !strings.HasPrefix(r.URL.Path, string(p)) {
+		return res, err
+	}
+	res.Body.Close()
+	return &http.Response{
+		StatusCode: http.StatusBadGateway,
+		Status:     "502 Bad Gateway",
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("bad gateway")),
+		Request:    r,
+	}, nil
+}
+
+func TestGenerateKeyNotRetried(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	client := srv.Client(lighthouse.WithHTTPClient(&http.Client{Transport: badGateway("/api/ipns/generate_key")}))
+
+	_, err := client.IPNS().GenerateKey(ctx, "site")
+	var le *lighthouse.Error
+	if !errors.As(err, &le) || le.Status != http.StatusBadGateway {
+		t.Errorf("err = %v, want the 502 rather than a retry's conflict", err)
+	}
+	if hits := srv.Hits(http.MethodGet, "/api/ipns/generate_key"); hits != 1 {
+		t.Errorf("generate_key hits = %d, want 1", hits)
+	}
+	// The key was created; a caller can find it instead of retrying.
+	if keys, err := srv.Client().IPNS().ListKeys(ctx); err != nil || len(keys) != 1 {
+		t.Errorf("ListKeys = %+v, %v", keys, err)
+	}
+}
+
+func TestPublishRecordNotRetried(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	res, err := srv.Client().Storage().UploadText(ctx, "site.txt", "site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Client().IPNS().GenerateKey(ctx, "site"); err != nil {
+		t.Fatal(err)
+	}
+	client := srv.Client(lighthouse.WithHTTPClient(&http.Client{Transport: badGateway("/api/ipns/publish_recored")}))
+	if _, err := client.IPNS().PublishRecord(ctx, res.Hash, "site"); err == nil {
+		t.Error("PublishRecord: want the 502")
+	}
+	if hits := srv.Hits(http.MethodGet, "/api/ipns/publish_recored"); hits != 1 {
+		t.Errorf("publish_recored hits = %d, want 1", hits)
+	}
+}
+
+// Read-only IPNS calls keep the client's retry policy.
+func TestListKeysRetried(t *testing.T) {
+	srv := lighthousetest.NewServer(t)
+	srv.Fail(http.MethodGet, "/api/ipns/get_ipns_records", http.StatusBadGateway, 1)
+	if _, err := srv.Client().IPNS().ListKeys(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if hits := srv.Hits(http.MethodGet, "/api/ipns/get_ipns_records"); hits != 2 {
+		t.Errorf("get_ipns_records hits = %d, want 2", hits)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lhotel
+
+go 1.23.6
+
+require (
+	github.com/lighthouse-web3/lighthouse-go-sdk v0.1.0
+	go.opentelemetry.io/otel v1.36.0
+	go.opentelemetry.io/otel/metric v1.36.0
+	go.opentelemetry.io/otel/trace v1.36.0
+)
@@ -0,0 +1,102 @@
+// Package lhotel adapts OpenTelemetry tracers and meters to the SDK's
+// telemetry interfaces. It lives in its own module so the core SDK stays
+// free of OpenTelemetry dependencies.
+//
+//	cli := lighthouse.NewClient(nil,
+//		lighthouse.WithTracer(lhotel.Tracer(otel.GetTracerProvider())),
+//		lighthouse.WithMeter(lhotel.Meter(otel.GetMeterProvider())),
+//	)
+package lhotel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
+)
+
+// ScopeName is the instrumentation scope used for tracers and meters.
+const ScopeName = "github.com/lighthouse-web3/lighthouse-go-sdk"
+
+// Tracer returns a telemetry.Tracer backed by tp.
+func Tracer(tp trace.TracerProvider) telemetry.Tracer {
+	return tracer{t: tp.Tracer(ScopeName)}
+}
+
+// Meter returns a telemetry.Meter backed by mp.
+func Meter(mp metric.MeterProvider) telemetry.Meter {
+	return meter{m: mp.Meter(ScopeName)}
+}
+
+type tracer struct{ t trace.Tracer }
+
+func (t tracer) Start(ctx context.Context, name string, attrs ...telemetry.Attr) (context.Context, telemetry.Span) {
+	ctx, s := t.t.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(convert(attrs)...))
+	return ctx, span{s: s}
+}
+
+type span struct{ s trace.Span }
+
+func (s span) SetAttributes(attrs ...telemetry.Attr) { s.s.SetAttributes(convert(attrs)...) }
+
+func (s span) RecordError(err error) {
+	s.s.RecordError(err)
+	s.s.SetStatus(codes.Error, err.Error())
+}
+
+func (s span) End() { s.s.End() }
+
+type meter struct{ m metric.Meter }
+
+func (m meter) Int64Counter(name, unit, description string) telemetry.Int64Counter {
+	c, err := m.m.Int64Counter(name, metric.WithUnit(unit), metric.WithDescription(description))
+	if err != nil {
+		return telemetry.NoopMeter{}.Int64Counter(name, unit, description)
+	}
+	return counter{c: c}
+}
+
+func (m meter) Float64Histogram(name, unit, description string) telemetry.Float64Histogram {
+	h, err := m.m.Float64Histogram(name, metric.WithUnit(unit), metric.WithDescription(description))
+	if err != nil {
+		return telemetry.NoopMeter{}.Float64Histogram(name, unit, description)
+	}
+	return histogram{h: h}
+}
+
+type counter struct{ c metric.Int64Counter }
+
+func (c counter) Add(ctx context.Context, n int64, attrs ...telemetry.Attr) {
+	c.c.Add(ctx, n, metric.WithAttributes(convert(attrs)...))
+}
+
+type histogram struct{ h metric.Float64Histogram }
+
+func (h histogram) Record(ctx context.Context, v float64, attrs ...telemetry.Attr) {
+	h.h.Record(ctx, v, metric.WithAttributes(convert(attrs)...))
+}
+
+func convert(attrs []telemetry.Attr) []attribute.KeyValue {
+	out := make([]attribute.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			out = append(out, attribute.String(a.Key, v))
+		case int64:
+			out = append(out, attribute.Int64(a.Key, v))
+		case int:
+			out = append(out, attribute.Int(a.Key, v))
+		case float64:
+			out = append(out, attribute.Float64(a.Key, v))
+		case bool:
+			out = append(out, attribute.Bool(a.Key, v))
+		}
+	}
+	return out
+}
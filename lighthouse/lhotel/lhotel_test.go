@@ -0,0 +1,227 @@
+package lhotel_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricembedded "go.opentelemetry.io/otel/metric/embedded"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lhotel"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
+)
+
+// recorder is a minimal in-memory trace and metric backend.
+type recorder struct {
+	mu         sync.Mutex
+	spans      []*span
+	counters   map[string][]point
+	histograms map[string][]point
+}
+
+type point struct {
+	value float64
+	attrs attribute.Set
+}
+
+func newRecorder() *recorder {
+	return &recorder{counters: map[string][]point{}, histograms: map[string][]point{}}
+}
+
+type tracerProvider struct {
+	embedded.TracerProvider
+	r *recorder
+}
+
+func (p tracerProvider) Tracer(string, ...trace.TracerOption) trace.Tracer { return tracer{r: p.r} }
+
+type tracer struct {
+	embedded.Tracer
+	r *recorder
+}
+
+func (t tracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	s := &span{name: name, kind: cfg.SpanKind(), attrs: map[attribute.Key]attribute.Value{}}
+	for _, kv := range cfg.Attributes() {
+		s.attrs[kv.Key] = kv.Value
+	}
+	t.r.mu.Lock()
+	t.r.spans = append(t.r.spans, s)
+	t.r.mu.Unlock()
+	return ctx, s
+}
+
+type span struct {
+	tracenoop.Span
+	name   string
+	kind   trace.SpanKind
+	attrs  map[attribute.Key]attribute.Value
+	errs   []error
+	status codes.Code
+	ended  bool
+}
+
+func (s *span) SetAttributes(kvs ...attribute.KeyValue) {
+	for _, kv := range kvs {
+		s.attrs[kv.Key] = kv.Value
+	}
+}
+
+func (s *span) RecordError(err error, _ ...trace.EventOption) { s.errs = append(s.errs, err) }
+func (s *span) SetStatus(c codes.Code, _ string)              { s.status = c }
+func (s *span) End(...trace.SpanEndOption)                    { s.ended = true }
+
+type meterProvider struct {
+	metricembedded.MeterProvider
+	r *recorder
+}
+
+func (p meterProvider) Meter(string, ...metric.MeterOption) metric.Meter {
+	return meter{r: p.r}
+}
+
+type meter struct {
+	metricnoop.Meter
+	r *recorder
+}
+
+func (m meter) Int64Counter(name string, _ ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return counter{name: name, r: m.r}, nil
+}
+
+func (m meter) Float64Histogram(name string, _ ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return histogram{name: name, r: m.r}, nil
+}
+
+type counter struct {
+	metricnoop.Int64Counter
+	name string
+	r    *recorder
+}
+
+func (c counter) Add(_ context.Context, n int64, opts ...metric.AddOption) {
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+	c.r.counters[c.name] = append(c.r.counters[c.name], point{float64(n), metric.NewAddConfig(opts).Attributes()})
+}
+
+type histogram struct {
+	metricnoop.Float64Histogram
+	name string
+	r    *recorder
+}
+
+func (h histogram) Record(_ context.Context, v float64, opts ...metric.RecordOption) {
+	h.r.mu.Lock()
+	defer h.r.mu.Unlock()
+	h.r.histograms[h.name] = append(h.r.histograms[h.name], point{v, metric.NewRecordConfig(opts).Attributes()})
+}
+
+func (r *recorder) span(t *testing.T, name string) *span {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.spans {
+		if s.name == name {
+			return s
+		}
+	}
+	t.Fatalf("no span named %s", name)
+	return nil
+}
+
+func client(srv *lighthousetest.Server, r *recorder) *lighthouse.Client {
+	return srv.Client(
+		lighthouse.WithTracer(lhotel.Tracer(tracerProvider{r: r})),
+		lighthouse.WithMeter(lhotel.Meter(meterProvider{r: r})),
+	)
+}
+
+func TestSpanAttributes(t *testing.T) {
+	srv := lighthousetest.NewServer(t)
+	srv.Fail(http.MethodPost, "/api/v0/add", http.StatusServiceUnavailable, 1)
+	r := newRecorder()
+
+	res, err := client(srv, r).Storage().UploadText(context.Background(), "a.txt", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := r.span(t, "storage.UploadReader")
+	if !s.ended || s.kind != trace.SpanKindClient {
+		t.Errorf("span ended=%v kind=%v, want an ended client span", s.ended, s.kind)
+	}
+	for key, want := range map[string]attribute.Value{
+		telemetry.AttrCID:        attribute.StringValue(res.Hash),
+		telemetry.AttrRetryCount: attribute.Int64Value(1),
+		telemetry.AttrHTTPStatus: attribute.Int64Value(http.StatusOK),
+	} {
+		if got := s.attrs[attribute.Key(key)]; got != want {
+			t.Errorf("%s = %v, want %v", key, got.Emit(), want.Emit())
+		}
+	}
+	if len(s.errs) != 0 || s.status != codes.Unset {
+		t.Errorf("successful span recorded errors %v, status %v", s.errs, s.status)
+	}
+}
+
+func TestSpanError(t *testing.T) {
+	srv := lighthousetest.NewServer(t)
+	srv.Fail("", "/api/lighthouse/deal_status", http.StatusNotFound, -1)
+	r := newRecorder()
+
+	if _, err := client(srv, r).Deals().Status(context.Background(), "bafkreiabc"); err == nil {
+		t.Fatal("want an error")
+	}
+	s := r.span(t, "deals.Status")
+	if len(s.errs) != 1 || s.status != codes.Error {
+		t.Errorf("span errors %v, status %v; want the error recorded", s.errs, s.status)
+	}
+	if got := s.attrs[telemetry.AttrCID].AsString(); got != "bafkreiabc" {
+		t.Errorf("%s = %q", telemetry.AttrCID, got)
+	}
+	if got := s.attrs[telemetry.AttrHTTPStatus].AsInt64(); got != http.StatusNotFound {
+		t.Errorf("%s = %d, want 404", telemetry.AttrHTTPStatus, got)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	srv := lighthousetest.NewServer(t)
+	srv.Fail(http.MethodPost, "/api/v0/add", http.StatusServiceUnavailable, 1)
+	r := newRecorder()
+	if _, err := client(srv, r).Storage().UploadText(context.Background(), "a.txt", "hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d := r.histograms[telemetry.MetricOperationDuration]
+	if len(d) != 1 {
+		t.Fatalf("%s = %+v, want one sample for the upload", telemetry.MetricOperationDuration, d)
+	}
+	op, _ := d[0].attrs.Value(telemetry.AttrOperation)
+	failed, _ := d[0].attrs.Value("error")
+	if op.AsString() != "storage.UploadReader" || failed.AsBool() || d[0].value <= 0 {
+		t.Errorf("duration sample = %v with %s=%q error=%v", d[0].value, telemetry.AttrOperation, op.AsString(), failed.AsBool())
+	}
+	if c := r.counters[telemetry.MetricRetries]; len(c) != 1 || c[0].value != 1 {
+		t.Errorf("%s = %+v, want one retry", telemetry.MetricRetries, c)
+	}
+	var uploaded float64
+	for _, p := range r.counters[telemetry.MetricUploadedBytes] {
+		uploaded += p.value
+	}
+	if uploaded <= 0 {
+		t.Errorf("%s = %v, want the bytes sent", telemetry.MetricUploadedBytes, uploaded)
+	}
+}
@@ -0,0 +1,338 @@
+package lighthousetest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+// ErrNoInteraction is returned in replay mode for a request the cassette
+// has no unused recording of.
+var ErrNoInteraction = errors.New("lighthousetest: no recorded interaction")
+
+// CassetteMode selects whether a Cassette talks to the network.
+type CassetteMode int
+
+const (
+	// Replay serves recorded responses and never touches the network.
+	Replay CassetteMode = iota
+	// Record forwards requests and overwrites the cassette file when the
+	// test ends. Request bodies are read fully before being forwarded, so
+	// streamed uploads go out with a fixed Content-Length instead of chunked
+	// encoding and are held in memory.
+	Record
+	// ReplayOrRecord replays an existing cassette file and records one
+	// otherwise.
+	ReplayOrRecord
+)
+
+// Interaction is one recorded request/response pair.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string `json:"method"`
+	// URL is the path and normalized query; the host is not recorded so
+	// cassettes replay against any configured hosts.
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	// Body holds JSON and form request bodies. Multipart bodies are only
+	// hashed: BodySHA256 and BodySize describe them.
+	Body       string `json:"body,omitempty"`
+	BodySHA256 string `json:"bodySHA256,omitempty"`
+	BodySize   int64  `json:"bodySize"`
+}
+
+type RecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	// Body holds UTF-8 responses; anything else is in BodyBase64.
+	Body       string `json:"body,omitempty"`
+	BodyBase64 []byte `json:"bodyBase64,omitempty"`
+}
+
+type cassetteFile struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Cassette is an http.RoundTripper that records Lighthouse traffic to a
+// JSON fixture file or replays it. Use it with lighthouse.WithHTTPClient:
+//
+//	cas := lighthousetest.NewCassette(t, "testdata/upload.json", lighthousetest.ReplayOrRecord)
+//	client := lighthouse.NewClient(nil, lighthouse.WithHTTPClient(cas.HTTPClient()))
+//
+// Before anything is written, bearer tokens, Set-Cookie headers and the API
+// key in create_api_key responses are redacted and multipart bodies hashed.
+// Replayed requests match on method, path and normalized query, each
+// recording being used once in order.
+type Cassette struct {
+	tb    testing.TB
+	path  string
+	mode  CassetteMode
+	next  http.RoundTripper
+	scrub func(*Interaction)
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+type CassetteOption func(*Cassette)
+
+// WithRecordTransport sets the transport recorded requests are sent with
+// (default http.DefaultTransport).
+func WithRecordTransport(rt http.RoundTripper) CassetteOption {
+	return func(c *Cassette) { c.next = rt }
+}
+
+// WithScrubber edits each interaction before it is saved, after the default
+// redactions, e.g. to mask wallet addresses.
+func WithScrubber(fn func(*Interaction)) CassetteOption {
+	return func(c *Cassette) { c.scrub = fn }
+}
+
+// NewCassette loads or prepares the cassette at path. In replay mode a
+// missing or unreadable file fails the test; in record mode the file is
+// written when the test ends.
+func NewCassette(tb testing.TB, path string, mode CassetteMode, opts ...CassetteOption) *Cassette {
+	tb.Helper()
+	c := &Cassette{tb: tb, path: path, mode: mode, next: http.DefaultTransport}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.mode == ReplayOrRecord {
+		c.mode = Replay
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			c.mode = Record
+		}
+	}
+
+	if c.mode == Record {
+		tb.Cleanup(func() {
+			if err := c.Save(); err != nil {
+				tb.Errorf("lighthousetest: saving cassette: %v", err)
+			}
+		})
+		return c
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("lighthousetest: loading cassette: %v", err)
+	}
+	var f cassetteFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		tb.Fatalf("lighthousetest: loading cassette %s: %v", path, err)
+	}
+	c.interactions = f.Interactions
+	c.used = make([]bool, len(f.Interactions))
+	return c
+}
+
+// HTTPClient returns an *http.Client using c as its transport.
+func (c *Cassette) HTTPClient() *http.Client {
+	return &http.Client{Transport: c}
+}
+
+// Recording reports whether c forwards requests to the network.
+func (c *Cassette) Recording() bool { return c.mode == Record }
+
+// Interactions returns a copy of the recorded or loaded interactions.
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Interaction(nil), c.interactions...)
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	if c.mode == Record {
+		return c.record(req)
+	}
+	return c.replay(req)
+}
+
+func (c *Cassette) record(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	out.TransferEncoding = nil
+	if req.Body == nil {
+		out.Body = nil
+	}
+
+	res, err := c.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	in := Interaction{
+		Request: recordRequest(req, body),
+		Response: RecordedResponse{
+			Status: res.StatusCode,
+			Header: res.Header.Clone(),
+		},
+	}
+	// Drop the date so re-recording an unchanged exchange leaves no diff.
+	in.Response.Header.Del("Date")
+	if utf8.Valid(resBody) {
+		in.Response.Body = string(resBody)
+	} else {
+		in.Response.BodyBase64 = resBody
+	}
+	redactResponse(req, &in.Response)
+	if c.scrub != nil {
+		c.scrub(&in)
+	}
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, in)
+	c.mu.Unlock()
+	return res, nil
+}
+
+func recordRequest(req *http.Request, body []byte) RecordedRequest {
+	r := RecordedRequest{
+		Method:   req.Method,
+		URL:      normalizeURL(req.URL),
+		Header:   make(http.Header),
+		BodySize: int64(len(body)),
+	}
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		r.BodySHA256 = hex.EncodeToString(sum[:])
+	}
+	for _, k := range []string{"Accept", "Range", "If-Range", "Content-Type"} {
+		if v := req.Header.Get(k); v != "" {
+			r.Header.Set(k, v)
+		}
+	}
+	if req.Header.Get("Authorization") != "" {
+		r.Header.Set("Authorization", "Bearer [REDACTED]")
+	}
+	mt, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if strings.HasPrefix(mt, "multipart/") {
+		// The boundary is random per request.
+		r.Header.Set("Content-Type", mt)
+	} else if len(body) > 0 && utf8.Valid(body) {
+		r.Body = string(body)
+	}
+	return r
+}
+
+// redacted replaces secrets in recordings.
+const redacted = "[REDACTED]"
+
+// redactResponse removes credentials the server hands out: cookies, and the
+// API key minted by create_api_key (kept as a JSON string so replays still
+// decode).
+func redactResponse(req *http.Request, r *RecordedResponse) {
+	if v := r.Header.Values("Set-Cookie"); len(v) > 0 {
+		r.Header["Set-Cookie"] = slices.Repeat([]string{redacted}, len(v))
+	}
+	if strings.HasSuffix(req.URL.Path, "/api/auth/create_api_key") && r.Status/100 == 2 {
+		r.Body, r.BodyBase64 = strconv.Quote(redacted)+"\n", nil
+	}
+}
+
+func (c *Cassette) replay(req *http.Request) (*http.Response, error) {
+	// Drain the body so streaming uploads finish and report progress.
+	if req.Body != nil {
+		io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+	key := req.Method + " " + normalizeURL(req.URL)
+
+	c.mu.Lock()
+	found := -1
+	for i, in := range c.interactions {
+		if !c.used[i] && in.Request.Method+" "+in.Request.URL == key {
+			found = i
+			c.used[i] = true
+			break
+		}
+	}
+	c.mu.Unlock()
+	if found < 0 {
+		c.tb.Errorf("lighthousetest: cassette %s has no unused interaction for %s", c.path, key)
+		return nil, fmt.Errorf("%w for %s", ErrNoInteraction, key)
+	}
+
+	rec := c.interactions[found].Response
+	body := []byte(rec.Body)
+	if rec.BodyBase64 != nil {
+		body = rec.BodyBase64
+	}
+	header := rec.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.Status, http.StatusText(rec.Status)),
+		StatusCode:    rec.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Save writes the recorded interactions to the cassette file. It is called
+// automatically at the end of a recording test.
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	b, err := json.MarshalIndent(cassetteFile{Version: 1, Interactions: c.interactions}, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, append(b, '\n'), 0o644)
+}
+
+// normalizeURL returns the path and the query with keys sorted.
+func normalizeURL(u *url.URL) string {
+	p := u.EscapedPath()
+	if q := u.Query().Encode(); q != "" {
+		p += "?" + q
+	}
+	return p
+}
@@ -0,0 +1,71 @@
+package lighthousetest_test
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/auth"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestCassetteRedactsSecrets(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	withCookie := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		res, err := http.DefaultTransport.RoundTrip(r)
+		if err == nil {
+			res.Header.Add("Set-Cookie", "session=s3cret")
+		}
+		return res, err
+	})
+	path := filepath.Join(t.TempDir(), "auth.json")
+	signer, err := auth.NewKeySigner("0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cas := lighthousetest.NewCassette(t, path, lighthousetest.Record, lighthousetest.WithRecordTransport(withCookie))
+	client := lighthouse.NewClient(cas.HTTPClient(), lighthouse.WithHosts(srv.URL, srv.URL, srv.URL), lighthouse.WithAPIKey(srv.APIKey()))
+	key, err := client.Auth().CreateAPIKey(ctx, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.User().Usage(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, in := range cas.Interactions() {
+		if strings.Contains(in.Response.Body, key) {
+			t.Errorf("%s %s: recorded response contains the API key", in.Request.Method, in.Request.URL)
+		}
+		for _, v := range in.Response.Header.Values("Set-Cookie") {
+			if v != "[REDACTED]" {
+				t.Errorf("%s: Set-Cookie recorded as %q", in.Request.URL, v)
+			}
+		}
+		if a := in.Request.Header.Get("Authorization"); a != "" && a != "Bearer [REDACTED]" {
+			t.Errorf("%s: Authorization recorded as %q", in.Request.URL, a)
+		}
+	}
+	if err := cas.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	replay := lighthousetest.NewCassette(t, path, lighthousetest.Replay)
+	client = lighthouse.NewClient(replay.HTTPClient(), lighthouse.WithHosts("http://api", "http://upload", "http://gw"))
+	got, err := client.Auth().CreateAPIKey(ctx, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "[REDACTED]" {
+		t.Errorf("replayed key = %q, want the redacted placeholder", got)
+	}
+}
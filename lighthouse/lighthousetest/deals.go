@@ -0,0 +1,145 @@
+package lighthousetest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/filecoin"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/unixfs"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+// DealDuration is the length, in epochs, of the deals the server makes
+// (about 180 days).
+const DealDuration = 518400
+
+type dealState struct {
+	phase schema.DealPhase
+	id    int64 // chain deal ID of the first replica
+	start int64 // epoch, set on publication
+}
+
+// queueDeals starts the deal pipeline for c if it is not already running.
+// s.mu must be held.
+func (s *Server) queueDeals(c string) *dealState {
+	d, ok := s.deals[c]
+	if !ok {
+		d = &dealState{id: int64(100000 + 100*len(s.deals))}
+		s.deals[c] = d
+	}
+	return d
+}
+
+// handleDealStatus reports the deals for a CID in their current phase and
+// then, unless WithManualDeals was given, advances them one phase.
+func (s *Server) handleDealStatus(w http.ResponseWriter, r *http.Request) {
+	c := r.URL.Query().Get("cid")
+	if _, err := cid.Parse(c); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.deals[c]
+	if !ok {
+		writeJSON(w, []schema.DealStatus{})
+		return
+	}
+	writeJSON(w, s.dealStatuses(c, d))
+	if s.autoDeals {
+		d.advance()
+	}
+}
+
+// AdvanceDeals moves the deals for c one phase forward and returns the new
+// phase. It reports false if c was never uploaded or pinned.
+func (s *Server) AdvanceDeals(c string) (schema.DealPhase, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.deals[c]
+	if !ok {
+		return schema.DealQueued, false
+	}
+	d.advance()
+	return d.phase, true
+}
+
+// SetDealPhase puts the deals for c in phase p, creating them if needed.
+func (s *Server) SetDealPhase(c string, p schema.DealPhase) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.queueDeals(c)
+	for d.phase < p {
+		d.advance()
+	}
+	d.phase = p
+}
+
+func (d *dealState) advance() {
+	if d.phase == schema.DealActive {
+		return
+	}
+	d.phase++
+	if d.phase == schema.DealPublished {
+		d.start = filecoin.EpochAt(time.Now()) + 2880
+	}
+}
+
+// dealStatuses renders the replicas of d the way Lighthouse fills them in
+// phase by phase. Queued deals have no entries yet. s.mu must be held.
+func (s *Server) dealStatuses(c string, d *dealState) []schema.DealStatus {
+	out := []schema.DealStatus{}
+	if d.phase == schema.DealQueued {
+		return out
+	}
+	size, _ := s.contentSize(mustParse(c))
+	aggregate := fakeCID("aggregate", c)
+	for i := 0; i < s.replicas; i++ {
+		ds := schema.DealStatus{
+			StorageProvider: fmt.Sprintf("f0%d", 1000+i),
+			Miner:           fmt.Sprintf("f0%d", 1000+i),
+			DealUUID:        fmt.Sprintf("%s-%d", fakeCID("deal", c)[8:44], i),
+			AggregateIn:     aggregate,
+			PieceCID:        fakeCID("piece", c),
+			PayloadCID:      c,
+			PieceSize:       nextPow2(size),
+			CarFileSize:     size,
+			Content:         size,
+			DealStatus:      "Aggregated",
+			LastUpdate:      time.Now().UnixMilli(),
+		}
+		if d.phase >= schema.DealPublished {
+			ds.ChainDealID = d.id + int64(i)
+			ds.DealID = ds.ChainDealID
+			ds.PublishCID = fakeCID("publish", c)
+			ds.StartEpoch = d.start
+			ds.EndEpoch = d.start + DealDuration
+			ds.DealStatus = "Sealing"
+		}
+		if d.phase == schema.DealActive {
+			ds.DealStatus = "Active"
+		}
+		out = append(out, ds)
+	}
+	return out
+}
+
+// fakeCID derives a stable placeholder CID for deal metadata.
+func fakeCID(kind, c string) string {
+	return cid.Sum(unixfs.CodecRaw, []byte(kind+"\x00"+c)).String()
+}
+
+func mustParse(c string) cid.CID {
+	id, _ := cid.Parse(c)
+	return id
+}
+
+func nextPow2(n int64) int64 {
+	p := int64(128)
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
@@ -0,0 +1,150 @@
+package lighthousetest
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+// Owner is the wallet address reported as the owner of every file.
+const Owner = "0x0000000000000000000000000000000000000000"
+
+// addRecord lists an upload under the account and queues its deals. s.mu
+// must be held.
+func (s *Server) addRecord(name, c string, size int64) {
+	s.nextID++
+	now := time.Now().UnixMilli()
+	s.files = append(s.files, &schema.FileEntry{
+		Name:            path.Base("/" + name),
+		CID:             c,
+		Size:            size,
+		ID:              "file-" + strconv.Itoa(s.nextID),
+		PublicKey:       Owner,
+		FileSizeInBytes: size,
+		MimeType:        mime.TypeByExtension(path.Ext(name)),
+		CreatedAt:       now,
+		LastUpdate:      now,
+	})
+	s.queueDeals(c)
+}
+
+// used returns the bytes counted against the quota. s.mu must be held.
+func (s *Server) used() int64 {
+	var n int64
+	for _, f := range s.files {
+		n += f.Size
+	}
+	return n
+}
+
+// Files returns the account's file records, newest first.
+func (s *Server) Files() []schema.FileEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]schema.FileEntry, 0, len(s.files))
+	for i := len(s.files) - 1; i >= 0; i-- {
+		out = append(out, *s.files[i])
+	}
+	return out
+}
+
+// handleList pages through the files newest first. lastKey is the ID of the
+// last file of the previous page; it is omitted on the final page.
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start := len(s.files) - 1
+	if key := r.URL.Query().Get("lastKey"); key != "" {
+		i := slices.IndexFunc(s.files, func(f *schema.FileEntry) bool { return f.ID == key })
+		if i < 0 {
+			writeError(w, http.StatusBadRequest, "invalid lastKey")
+			return
+		}
+		start = i - 1
+	}
+
+	out := struct {
+		FileList   []schema.FileEntry `json:"fileList"`
+		TotalFiles int                `json:"totalFiles"`
+		LastKey    *string            `json:"lastKey"`
+	}{FileList: []schema.FileEntry{}, TotalFiles: len(s.files)}
+	i := start
+	for ; i >= 0 && len(out.FileList) < s.pageSize; i-- {
+		out.FileList = append(out.FileList, *s.files[i])
+	}
+	if i >= 0 {
+		out.LastKey = &out.FileList[len(out.FileList)-1].ID
+	}
+	writeJSON(w, out)
+}
+
+func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
+	c := r.URL.Query().Get("cid")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.files) - 1; i >= 0; i-- {
+		if f := s.files[i]; f.CID == c {
+			writeJSON(w, schema.FileInfo{
+				FileSizeInBytes: f.FileSizeInBytes,
+				CID:             f.CID,
+				Encryption:      f.Encryption,
+				FileName:        f.Name,
+				MimeType:        f.MimeType,
+			})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "file not found")
+}
+
+// handlePin records a pin by CID. Content the server already holds is
+// sized from its blocks; anything else is recorded with size 0.
+func (s *Server) handlePin(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		CID      string `json:"cid"`
+		FileName string `json:"fileName"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	c, err := cid.Parse(body.CID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	size, _ := s.contentSize(c)
+	s.addRecord(body.FileName, body.CID, size)
+	writeJSON(w, map[string]any{"data": map[string]string{"cid": body.CID}})
+}
+
+// handleDelete removes a file record. Its blocks stay available, as they
+// would on the network.
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.files, func(f *schema.FileEntry) bool { return f.ID == id })
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+	s.files = slices.Delete(s.files, i, i+1)
+	writeJSON(w, map[string]string{"message": "File deleted successfully"})
+}
+
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, schema.Usage{DataLimit: s.dataLimit, DataUsed: s.used()})
+}
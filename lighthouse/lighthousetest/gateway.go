@@ -0,0 +1,183 @@
+package lighthousetest
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/car"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/unixfs"
+)
+
+// handleGateway serves /ipfs/<cid>[/path] and /ipns/<key>[/path]. Files
+// support Range and If-Range against a CID ETag; ?format=raw returns the
+// resolved block and ?format=car a DFS CARv1 of the path and its target
+// (dag-scope=entity stops at the target directory's own block).
+func (s *Server) handleGateway(w http.ResponseWriter, r *http.Request) {
+	ns, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	first, sub, _ := strings.Cut(rest, "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ns == "ipns" {
+		k := s.key(first)
+		if k == nil || k.cid == "" {
+			http.Error(w, "ipns name not found: "+first, http.StatusNotFound)
+			return
+		}
+		first = k.cid
+	}
+	root, err := cid.Parse(first)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var names []string
+	if sub = strings.Trim(sub, "/"); sub != "" {
+		names = strings.Split(sub, "/")
+	}
+	path, err := s.resolve(root, names)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	target := path[len(path)-1]
+
+	format := r.URL.Query().Get("format")
+	accept := r.Header.Get("Accept")
+	switch {
+	case format == "raw" || strings.HasPrefix(accept, "application/vnd.ipld.raw"):
+		data, _ := s.node(target)
+		w.Header().Set("Content-Type", "application/vnd.ipld.raw")
+		w.Header().Set("ETag", `"`+target.String()+`.raw"`)
+		w.Write(data)
+		return
+	case format == "car" || strings.HasPrefix(accept, "application/vnd.ipld.car"):
+		s.serveCAR(w, path, r.URL.Query().Get("dag-scope") == "entity")
+		return
+	}
+
+	data, _ := s.node(target)
+	if target.Codec == unixfs.CodecDagPB {
+		n, d, err := decodeUnixFS(data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if d.Type == unixfs.TypeDirectory || d.Type == unixfs.TypeHAMTShard {
+			if idx, err := s.resolve(target, []string{"index.html"}); err == nil {
+				target = idx[len(idx)-1]
+			} else {
+				s.serveListing(w, r.URL.Path, n)
+				return
+			}
+		}
+	}
+	content, err := s.readFile(target, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	name := first
+	if len(names) > 0 {
+		name = names[len(names)-1]
+	}
+	w.Header().Set("ETag", `"`+target.String()+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=29030400, immutable")
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(content))
+}
+
+// resolve walks names from root through directory links and returns every
+// CID on the way, root first. s.mu must be held.
+func (s *Server) resolve(root cid.CID, names []string) ([]cid.CID, error) {
+	path := []cid.CID{root}
+	if _, err := s.node(root); err != nil {
+		return nil, err
+	}
+	cur := root
+next:
+	for _, name := range names {
+		data, _ := s.node(cur)
+		if cur.Codec != unixfs.CodecDagPB {
+			return nil, fmt.Errorf("%s is not a directory", cur)
+		}
+		n, d, err := decodeUnixFS(data)
+		if err != nil {
+			return nil, err
+		}
+		if d.Type != unixfs.TypeDirectory {
+			return nil, fmt.Errorf("%s is not a directory", cur)
+		}
+		for _, l := range n.Links {
+			if l.Name == name {
+				if _, err := s.node(l.CID); err != nil {
+					return nil, err
+				}
+				cur = l.CID
+				path = append(path, cur)
+				continue next
+			}
+		}
+		return nil, fmt.Errorf("no link named %q under %s", name, cur)
+	}
+	return path, nil
+}
+
+// serveCAR writes the blocks along path and then the target's DAG in
+// depth-first order, duplicates included. s.mu must be held.
+func (s *Server) serveCAR(w http.ResponseWriter, path []cid.CID, entity bool) {
+	w.Header().Set("Content-Type", "application/vnd.ipld.car; version=1; order=dfs; dups=y")
+	cw, err := car.NewWriter(w, path[0])
+	if err != nil {
+		return
+	}
+	for _, c := range path[:len(path)-1] {
+		data, _ := s.node(c)
+		if err := cw.Put(cid.Block{CID: c, Data: data}); err != nil {
+			return
+		}
+	}
+	var walk func(c cid.CID, top bool) error
+	walk = func(c cid.CID, top bool) error {
+		data, err := s.node(c)
+		if err != nil {
+			return err
+		}
+		if err := cw.Put(cid.Block{CID: c, Data: data}); err != nil {
+			return err
+		}
+		if c.Codec != unixfs.CodecDagPB {
+			return nil
+		}
+		n, d, err := decodeUnixFS(data)
+		if err != nil {
+			return err
+		}
+		if entity && top && d.Type == unixfs.TypeDirectory {
+			return nil
+		}
+		for _, l := range n.Links {
+			if err := walk(l.CID, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	walk(path[len(path)-1], true)
+}
+
+func (s *Server) serveListing(w http.ResponseWriter, p string, n unixfs.Node) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<html><body><h1>Index of %s</h1><ul>\n", html.EscapeString(p))
+	for _, l := range n.Links {
+		href := strings.TrimRight(p, "/") + "/" + url.PathEscape(l.Name)
+		fmt.Fprintf(w, "<li><a href=\"%s\">%s</a> %d</li>\n", html.EscapeString(href), html.EscapeString(l.Name), l.Tsize)
+	}
+	fmt.Fprint(w, "</ul></body></html>\n")
+}
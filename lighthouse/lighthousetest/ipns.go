@@ -0,0 +1,151 @@
+package lighthousetest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+type ipnsKey struct {
+	name, id   string
+	cid        string // empty until published
+	lastUpdate int64
+}
+
+// key returns the IPNS key with the given name or ID. s.mu must be held.
+func (s *Server) key(nameOrID string) *ipnsKey {
+	for _, k := range s.ipns {
+		if k.name == nameOrID || k.id == nameOrID {
+			return k
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleGenerateKey(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("keyName")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "keyName is required")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.key(name) != nil {
+		writeError(w, http.StatusBadRequest, "key with name '"+name+"' already exists")
+		return
+	}
+	sum := sha256.Sum256([]byte(name + "\x00" + strconv.Itoa(len(s.ipns))))
+	k := &ipnsKey{name: name, id: "k51qzi5uqu5d" + hex.EncodeToString(sum[:])[:50]}
+	s.ipns = append(s.ipns, k)
+	writeJSON(w, schema.IPNSKeyResponse{IPNSName: k.name, IPNSId: k.id})
+}
+
+func (s *Server) handlePublish(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	c, err := cid.Parse(q.Get("cid"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := s.key(q.Get("keyName"))
+	if k == nil {
+		writeError(w, http.StatusNotFound, "no key named "+q.Get("keyName"))
+		return
+	}
+	k.cid, k.lastUpdate = c.String(), time.Now().UnixMilli()
+	writeJSON(w, schema.IPNSPublishResponse{Name: k.id, Value: "/ipfs/" + k.cid})
+}
+
+func (s *Server) handleRecords(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := []schema.IPNSRecord{}
+	for _, k := range s.ipns {
+		out = append(out, schema.IPNSRecord{
+			IPNSName:   k.name,
+			IPNSId:     k.id,
+			PublicKey:  Owner,
+			CID:        k.cid,
+			LastUpdate: k.lastUpdate,
+		})
+	}
+	writeJSON(w, out)
+}
+
+func (s *Server) handleRemoveKey(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("keyName")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.ipns, func(k *ipnsKey) bool { return k.name == name })
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "no key named "+name)
+		return
+	}
+	k := s.ipns[i]
+	s.ipns = slices.Delete(s.ipns, i, i+1)
+	writeJSON(w, schema.IPNSRemoveResponse{Keys: []schema.IPNSKey{{Name: k.name, Id: k.id}}})
+}
+
+// handleAuthMessage issues the message a wallet signs to mint an API key.
+func (s *Server) handleAuthMessage(w http.ResponseWriter, r *http.Request) {
+	addr := strings.ToLower(r.URL.Query().Get("publicKey"))
+	if !isHex(strings.TrimPrefix(addr, "0x"), 40) {
+		writeError(w, http.StatusBadRequest, "invalid publicKey")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.minted++
+	msg := "Please sign this message to prove you are owner of this account: lighthousetest-" + strconv.Itoa(s.minted)
+	s.messages[addr] = msg
+	writeJSON(w, msg)
+}
+
+// handleCreateAPIKey exchanges a signed message for a new API key, which the
+// server accepts from then on. Signatures are checked for shape only; the
+// signer is not recovered.
+func (s *Server) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		PublicKey     string `json:"publicKey"`
+		SignedMessage string `json:"signedMessage"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	addr := strings.ToLower(body.PublicKey)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.messages[addr]; !ok {
+		writeError(w, http.StatusUnauthorized, "no pending message for "+body.PublicKey)
+		return
+	}
+	if !isHex(strings.TrimPrefix(body.SignedMessage, "0x"), 130) {
+		writeError(w, http.StatusUnauthorized, "invalid signature")
+		return
+	}
+	delete(s.messages, addr)
+	key := "lighthousetest-key-" + strconv.Itoa(s.minted)
+	if len(s.keys) > 0 {
+		s.keys = append(s.keys, key)
+	}
+	writeJSON(w, key)
+}
+
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
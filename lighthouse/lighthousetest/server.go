@@ -0,0 +1,298 @@
+// Package lighthousetest provides an in-memory Lighthouse server for tests.
+//
+// Server implements every endpoint the SDK calls on the API, upload and
+// gateway hosts: uploads are chunked into real UnixFS DAGs and served back
+// by CID, file listings paginate with lastKey, deals move through the
+// queued/aggregated/published/active phases, and IPNS keys resolve on the
+// gateway. Faults and latency can be injected per endpoint.
+//
+//	srv := lighthousetest.NewServer(t)
+//	client := srv.Client()
+//	res, err := client.Storage().UploadFile(ctx, "testdata/a.txt")
+package lighthousetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+// DefaultAPIKey is the key a Server accepts unless WithAPIKey is given.
+const DefaultAPIKey = "lighthousetest-api-key"
+
+// DefaultDataLimit is the account quota reported unless WithDataLimit is given.
+const DefaultDataLimit = 5 << 30
+
+// Server is an in-memory Lighthouse. It is safe for concurrent use.
+type Server struct {
+	// URL serves all three hosts (API, upload and gateway).
+	URL string
+
+	srv *httptest.Server
+
+	mu        sync.Mutex
+	keys      []string // accepted API keys; empty disables auth
+	blocks    map[string][]byte
+	files     []*schema.FileEntry
+	nextID    int
+	deals     map[string]*dealState
+	ipns      []*ipnsKey
+	messages  map[string]string // address -> pending auth message
+	minted    int
+	dataLimit int64
+	pageSize  int
+	replicas  int
+	autoDeals bool
+	latency   time.Duration
+	faults    []*fault
+	hits      map[string]int
+}
+
+type Option func(*Server)
+
+// WithAPIKey replaces the accepted API keys. Client uses the first one.
+func WithAPIKey(keys ...string) Option {
+	return func(s *Server) { s.keys = keys }
+}
+
+// WithoutAuth accepts requests with any or no Authorization header.
+func WithoutAuth() Option {
+	return func(s *Server) { s.keys = nil }
+}
+
+// WithPageSize sets how many files each files_uploaded page holds (default 10).
+func WithPageSize(n int) Option {
+	return func(s *Server) {
+		if n > 0 {
+			s.pageSize = n
+		}
+	}
+}
+
+// WithDataLimit sets the account quota; uploads beyond it fail with 402.
+func WithDataLimit(n int64) Option {
+	return func(s *Server) { s.dataLimit = n }
+}
+
+// WithReplicas sets how many storage deals are made for each upload (default 1).
+func WithReplicas(n int) Option {
+	return func(s *Server) {
+		if n > 0 {
+			s.replicas = n
+		}
+	}
+}
+
+// WithManualDeals stops deals advancing on each deal_status poll; use
+// AdvanceDeals or SetDealPhase instead.
+func WithManualDeals() Option {
+	return func(s *Server) { s.autoDeals = false }
+}
+
+// WithLatency delays every response by d.
+func WithLatency(d time.Duration) Option {
+	return func(s *Server) { s.latency = d }
+}
+
+// NewServer starts a Server and closes it when tb's test ends.
+func NewServer(tb testing.TB, opts ...Option) *Server {
+	tb.Helper()
+	s := &Server{
+		keys:      []string{DefaultAPIKey},
+		blocks:    make(map[string][]byte),
+		deals:     make(map[string]*dealState),
+		messages:  make(map[string]string),
+		hits:      make(map[string]int),
+		dataLimit: DefaultDataLimit,
+		pageSize:  10,
+		replicas:  1,
+		autoDeals: true,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.srv = httptest.NewServer(s.handler())
+	s.URL = s.srv.URL
+	tb.Cleanup(s.Close)
+	return s
+}
+
+func (s *Server) Close() { s.srv.Close() }
+
+// APIKey returns the key Client authenticates with, or "" when auth is off.
+func (s *Server) APIKey() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.keys) == 0 {
+		return ""
+	}
+	return s.keys[0]
+}
+
+// Client returns a client pointed at s with its API key and a fast retry
+// policy. opts are applied after these defaults.
+func (s *Server) Client(opts ...lighthouse.Option) *lighthouse.Client {
+	retry := lighthouse.DefaultRetryPolicy()
+	retry.InitialBackoff, retry.MaxBackoff, retry.Jitter = time.Millisecond, 10*time.Millisecond, 0
+
+	base := []lighthouse.Option{
+		lighthouse.WithHosts(s.URL, s.URL, s.URL),
+		lighthouse.WithAPIKey(s.APIKey()),
+		lighthouse.WithRetryPolicy(retry),
+	}
+	return lighthouse.NewClient(s.srv.Client(), append(base, opts...)...)
+}
+
+// SetLatency changes the delay added to every response.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	s.latency = d
+	s.mu.Unlock()
+}
+
+type fault struct {
+	method, path string
+	status       int
+	remaining    int
+}
+
+// Fail makes the next n requests matching method and path fail with status.
+// An empty method matches any method; a path ending in "/" matches every path
+// below it. n < 0 fails until ClearFaults.
+func (s *Server) Fail(method, path string, status, n int) {
+	if n == 0 {
+		return
+	}
+	s.mu.Lock()
+	s.faults = append(s.faults, &fault{method: method, path: path, status: status, remaining: n})
+	s.mu.Unlock()
+}
+
+// ClearFaults removes every injected failure.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	s.faults = nil
+	s.mu.Unlock()
+}
+
+// Hits reports how many requests for method and path s has received,
+// including failed ones.
+func (s *Server) Hits(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[method+" "+path]
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /api/v0/add", s.handleAdd)
+	mux.HandleFunc("POST /api/v0/block/put", s.handleBlockPut)
+	mux.HandleFunc("POST /api/v0/dag/import", s.handleDagImport)
+
+	mux.HandleFunc("GET /api/user/files_uploaded", s.handleList)
+	mux.HandleFunc("GET /api/lighthouse/file_info", s.handleInfo)
+	mux.HandleFunc("POST /api/lighthouse/pin", s.handlePin)
+	mux.HandleFunc("DELETE /api/user/delete_file", s.handleDelete)
+	mux.HandleFunc("GET /api/user/user_data_usage", s.handleUsage)
+	mux.HandleFunc("GET /api/lighthouse/deal_status", s.handleDealStatus)
+
+	mux.HandleFunc("GET /api/ipns/generate_key", s.handleGenerateKey)
+	mux.HandleFunc("GET /api/ipns/publish_recored", s.handlePublish)
+	mux.HandleFunc("GET /api/ipns/get_ipns_records", s.handleRecords)
+	mux.HandleFunc("DELETE /api/ipns/remove_key", s.handleRemoveKey)
+
+	mux.HandleFunc("GET /api/auth/get_message", s.handleAuthMessage)
+	mux.HandleFunc("POST /api/auth/create_api_key", s.handleCreateAPIKey)
+
+	mux.HandleFunc("GET /ipfs/", s.handleGateway)
+	mux.HandleFunc("HEAD /ipfs/", s.handleGateway)
+	mux.HandleFunc("GET /ipns/", s.handleGateway)
+	mux.HandleFunc("HEAD /ipns/", s.handleGateway)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		latency, f := s.admit(r)
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if f != nil {
+			writeError(w, f.status, "lighthousetest: injected fault")
+			return
+		}
+		if !s.authorized(r) {
+			writeError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// admit records the request and returns the latency and the injected
+// fault, if any, that apply to it.
+func (s *Server) admit(r *http.Request) (time.Duration, *fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hits[r.Method+" "+r.URL.Path]++
+
+	for i, f := range s.faults {
+		if f.method != "" && f.method != r.Method {
+			continue
+		}
+		if f.path != r.URL.Path && !(strings.HasSuffix(f.path, "/") && strings.HasPrefix(r.URL.Path, f.path)) {
+			continue
+		}
+		if f.remaining > 0 {
+			if f.remaining--; f.remaining == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return s.latency, f
+	}
+	return s.latency, nil
+}
+
+// authorized checks the bearer token on API and upload endpoints. The
+// gateway and the API-key minting flow are public.
+func (s *Server) authorized(r *http.Request) bool {
+	p := r.URL.Path
+	if strings.HasPrefix(p, "/ipfs/") || strings.HasPrefix(p, "/ipns/") || strings.HasPrefix(p, "/api/auth/") {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.keys) == 0 {
+		return true
+	}
+	key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	for _, k := range s.keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,241 @@
+package lighthousetest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+func TestUploadAndFetch(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	client := srv.Client()
+
+	const text = "hello from lighthousetest"
+	res, err := client.Storage().UploadText(ctx, "hello.txt", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := cid.Compute(strings.NewReader(text))
+	if res.Hash != want.String() {
+		t.Errorf("Hash = %s, want %s", res.Hash, want)
+	}
+	if files := srv.Files(); len(files) != 1 || files[0].CID != res.Hash || files[0].Name != "hello.txt" {
+		t.Errorf("Files() = %+v", files)
+	}
+
+	rc, _, err := client.Gateway().Get(ctx, res.Hash, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(got) != text {
+		t.Errorf("gateway body = %q, want %q", got, text)
+	}
+
+	rc, _, err = client.Gateway().GetRange(ctx, res.Hash, 6, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ = io.ReadAll(rc)
+	rc.Close()
+	if string(got) != "from" {
+		t.Errorf("range body = %q, want %q", got, "from")
+	}
+}
+
+func TestAuth(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t, lighthousetest.WithAPIKey("good"))
+
+	_, err := srv.Client(lighthouse.WithAPIKey("bad")).User().Usage(ctx)
+	if !errors.Is(err, lighthouse.ErrUnauthorized) {
+		t.Errorf("bad key: err = %v, want ErrUnauthorized", err)
+	}
+	if _, err := srv.Client().User().Usage(ctx); err != nil {
+		t.Errorf("good key: %v", err)
+	}
+
+	open := lighthousetest.NewServer(t, lighthousetest.WithoutAuth())
+	if _, err := open.Client(lighthouse.WithAPIKey("anything")).User().Usage(ctx); err != nil {
+		t.Errorf("WithoutAuth: %v", err)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t, lighthousetest.WithPageSize(2))
+	client := srv.Client()
+	for i := range 5 {
+		if _, err := client.Storage().UploadText(ctx, fmt.Sprintf("f%d.txt", i), fmt.Sprint(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page, err := client.Files().List(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Data) != 2 || page.LastKey == nil {
+		t.Fatalf("first page = %d files, LastKey %v", len(page.Data), page.LastKey)
+	}
+
+	var n int
+	for _, err := range client.Files().All(ctx) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != 5 {
+		t.Errorf("All yielded %d files, want 5", n)
+	}
+	if hits := srv.Hits(http.MethodGet, "/api/user/files_uploaded"); hits != 4 {
+		t.Errorf("files_uploaded hits = %d, want 4", hits)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	client := srv.Client()
+
+	srv.Fail(http.MethodPost, "/api/v0/add", http.StatusServiceUnavailable, 1)
+	if _, err := client.Storage().UploadText(ctx, "a.txt", "a"); err != nil {
+		t.Fatalf("retried upload: %v", err)
+	}
+	if hits := srv.Hits(http.MethodPost, "/api/v0/add"); hits != 2 {
+		t.Errorf("add hits = %d, want 2", hits)
+	}
+
+	srv.Fail("", "/api/", http.StatusNotFound, -1)
+	if _, err := client.User().Usage(ctx); !errors.Is(err, lighthouse.ErrNotFound) {
+		t.Errorf("persistent fault: err = %v, want ErrNotFound", err)
+	}
+	srv.ClearFaults()
+	if _, err := client.User().Usage(ctx); err != nil {
+		t.Errorf("after ClearFaults: %v", err)
+	}
+}
+
+func TestDataLimit(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t, lighthousetest.WithDataLimit(16))
+	client := srv.Client()
+
+	if _, err := client.Storage().UploadText(ctx, "small.txt", "fits"); err != nil {
+		t.Fatal(err)
+	}
+	usage, err := client.User().Usage(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.DataLimit != 16 || usage.DataUsed != 4 {
+		t.Errorf("usage = %+v, want 4 of 16", usage)
+	}
+	_, err = client.Storage().UploadText(ctx, "big.txt", strings.Repeat("x", 32))
+	if !errors.Is(err, lighthouse.ErrQuotaExceeded) {
+		t.Errorf("over quota: err = %v, want ErrQuotaExceeded", err)
+	}
+}
+
+func TestDealsAdvancePerPoll(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t, lighthousetest.WithReplicas(2))
+	client := srv.Client()
+	res, err := client.Storage().UploadText(ctx, "deal.txt", "deal me")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []schema.DealPhase{schema.DealQueued, schema.DealAggregated, schema.DealPublished, schema.DealActive, schema.DealActive} {
+		deals, err := client.Deals().Status(ctx, res.Hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want == schema.DealQueued {
+			if len(deals) != 0 {
+				t.Errorf("queued: got %d deals, want none", len(deals))
+			}
+			continue
+		}
+		if len(deals) != 2 {
+			t.Fatalf("%s: got %d deals, want 2", want, len(deals))
+		}
+		for _, d := range deals {
+			if d.Phase() != want {
+				t.Errorf("deal %s phase = %s, want %s", d.DealUUID, d.Phase(), want)
+			}
+		}
+	}
+}
+
+func TestManualDeals(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t, lighthousetest.WithManualDeals())
+	client := srv.Client()
+	res, err := client.Storage().UploadText(ctx, "deal.txt", "manual")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		if deals, _ := client.Deals().Status(ctx, res.Hash); len(deals) != 0 {
+			t.Fatalf("deals advanced without AdvanceDeals: %+v", deals)
+		}
+	}
+	if p, ok := srv.AdvanceDeals(res.Hash); !ok || p != schema.DealAggregated {
+		t.Errorf("AdvanceDeals = %s, %v", p, ok)
+	}
+	if _, ok := srv.AdvanceDeals("bafkreinotuploaded"); ok {
+		t.Error("AdvanceDeals reported an unknown CID")
+	}
+	srv.SetDealPhase(res.Hash, schema.DealActive)
+	deals, err := client.Deals().Status(ctx, res.Hash)
+	if err != nil || len(deals) != 1 || deals[0].Phase() != schema.DealActive {
+		t.Errorf("after SetDealPhase: %+v, %v", deals, err)
+	}
+}
+
+func TestIPNS(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	client := srv.Client()
+	res, err := client.Storage().UploadText(ctx, "site.txt", "published")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := client.IPNS().GenerateKey(ctx, "site")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.IPNS().PublishRecord(ctx, res.Hash, "site"); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get(srv.URL + "/ipns/" + key.IPNSId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "published" {
+		t.Errorf("ipns body = %q", body)
+	}
+
+	if _, err := client.IPNS().RemoveKey(ctx, "site"); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := client.IPNS().ListKeys(ctx); len(keys) != 0 {
+		t.Errorf("keys after RemoveKey = %+v", keys)
+	}
+}
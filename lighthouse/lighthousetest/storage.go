@@ -0,0 +1,413 @@
+package lighthousetest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/car"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/unixfs"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+// Block returns the stored block for c, if any.
+func (s *Server) Block(c string) ([]byte, bool) {
+	id, err := cid.Parse(c)
+	if err != nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.blocks[blockKey(id)]
+	return b, ok
+}
+
+// PutBlock stores a block as if it had been uploaded, without creating a
+// file record.
+func (s *Server) PutBlock(b cid.Block) error {
+	if err := b.CID.Verify(b.Data); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.blocks[blockKey(b.CID)] = b.Data
+	s.mu.Unlock()
+	return nil
+}
+
+// addedEntry is one line of an /api/v0/add response.
+type addedEntry struct {
+	name string
+	link unixfs.Link
+	size uint64 // content bytes
+}
+
+// handleAdd implements /api/v0/add: each multipart file is chunked into a
+// UnixFS DAG with the SDK's default settings. A single unwrapped file gets a
+// single JSON object back; anything else gets kubo's newline-delimited
+// listing with directories after their contents and the root last.
+func (s *Server) handleAdd(w http.ResponseWriter, r *http.Request) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	wrap, _ := strconv.ParseBool(r.URL.Query().Get("wrap-with-directory"))
+
+	var (
+		files   []addedEntry
+		pending []cid.Block
+		total   uint64
+	)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		name := partFileName(part)
+		if name == "" {
+			continue
+		}
+		b := cid.NewBuilder(cid.Options{OnBlock: func(blk cid.Block) error {
+			pending = append(pending, blk)
+			return nil
+		}})
+		if _, err := io.Copy(b, part); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		n, err := b.Sum()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		files = append(files, addedEntry{name: name, link: unixfs.Link{CID: n.CID, Tsize: n.Tsize}, size: n.FileSize})
+		total += n.FileSize
+	}
+	if len(files) == 0 {
+		writeError(w, http.StatusBadRequest, "file argument 'path' is required")
+		return
+	}
+
+	if len(files) == 1 && !wrap && !strings.Contains(files[0].name, "/") {
+		f := files[0]
+		if !s.store(w, pending, total, []addedEntry{f}) {
+			return
+		}
+		writeJSON(w, schema.UploadResult{Name: f.name, Hash: f.link.CID.String(), Size: strconv.FormatUint(f.link.Tsize, 10)})
+		return
+	}
+
+	dirs, tops := buildTree(files, wrap, func(blk cid.Block) { pending = append(pending, blk) })
+	if !s.store(w, pending, total, tops) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	for _, e := range append(files, dirs...) {
+		writeJSON(w, schema.UploadResult{Name: e.name, Hash: e.link.CID.String(), Size: strconv.FormatUint(e.link.Tsize, 10)})
+	}
+}
+
+// partFileName returns the full filename of a form part. Part.FileName
+// strips directories, which the directory upload relies on.
+func partFileName(p *multipart.Part) string {
+	_, params, err := mime.ParseMediaType(p.Header.Get("Content-Disposition"))
+	if err != nil {
+		return ""
+	}
+	return strings.Trim(path.Clean("/"+params["filename"]), "/")
+}
+
+type treeDir struct {
+	links []unixfs.Link
+	dirs  map[string]*treeDir
+	size  uint64
+}
+
+// buildTree links uploaded files into directory nodes. It returns the
+// directory entries in kubo's order (deepest first, root last) and the
+// top-level entries that become file records.
+func buildTree(files []addedEntry, wrap bool, put func(cid.Block)) (dirs, tops []addedEntry) {
+	root := &treeDir{dirs: map[string]*treeDir{}}
+	for _, f := range files {
+		d := root
+		parts := strings.Split(f.name, "/")
+		for _, p := range parts[:len(parts)-1] {
+			sub, ok := d.dirs[p]
+			if !ok {
+				sub = &treeDir{dirs: map[string]*treeDir{}}
+				d.dirs[p] = sub
+			}
+			d = sub
+		}
+		l := f.link
+		l.Name = parts[len(parts)-1]
+		d.links = append(d.links, l)
+		d.size += f.size
+	}
+
+	var link func(name string, d *treeDir) unixfs.Link
+	link = func(name string, d *treeDir) unixfs.Link {
+		names := make([]string, 0, len(d.dirs))
+		for n := range d.dirs {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		links := d.links
+		for _, n := range names {
+			sub := d.dirs[n]
+			l := link(path.Join(name, n), sub)
+			l.Name = n
+			links = append(links, l)
+			d.size += sub.size
+		}
+		blk := unixfs.DirectoryNode(links)
+		put(blk)
+		l := unixfs.Link{CID: blk.CID, Tsize: uint64(len(blk.Data))}
+		for _, c := range links {
+			l.Tsize += c.Tsize
+		}
+		dirs = append(dirs, addedEntry{name: name, link: l, size: d.size})
+		return l
+	}
+
+	if wrap {
+		link("", root)
+		return dirs, dirs[len(dirs)-1:]
+	}
+	names := make([]string, 0, len(root.dirs))
+	for n := range root.dirs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		link(n, root.dirs[n])
+		tops = append(tops, dirs[len(dirs)-1])
+	}
+	for _, l := range root.links {
+		for _, f := range files {
+			if f.name == l.Name {
+				tops = append(tops, f)
+			}
+		}
+	}
+	return dirs, tops
+}
+
+// store commits blocks and file records for an upload of size content
+// bytes, failing with 402 when it would exceed the quota.
+func (s *Server) store(w http.ResponseWriter, blocks []cid.Block, size uint64, records []addedEntry) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.used()+int64(size) > s.dataLimit {
+		writeError(w, http.StatusPaymentRequired, "data limit exceeded")
+		return false
+	}
+	for _, b := range blocks {
+		s.blocks[blockKey(b.CID)] = b.Data
+	}
+	for _, e := range records {
+		s.addRecord(e.name, e.link.CID.String(), int64(e.size))
+	}
+	return true
+}
+
+// handleBlockPut implements /api/v0/block/put for a single block.
+func (s *Server) handleBlockPut(w http.ResponseWriter, r *http.Request) {
+	data, err := readFilePart(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	codec := uint64(unixfs.CodecRaw)
+	switch c := r.URL.Query().Get("cid-codec"); c {
+	case "", "raw":
+	case "dag-pb":
+		codec = unixfs.CodecDagPB
+		if _, err := unixfs.DecodeNode(data); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported cid-codec "+c)
+		return
+	}
+	if mh := r.URL.Query().Get("mhtype"); mh != "" && mh != "sha2-256" {
+		writeError(w, http.StatusBadRequest, "unsupported mhtype "+mh)
+		return
+	}
+
+	c := cid.Sum(codec, data)
+	s.mu.Lock()
+	s.blocks[blockKey(c)] = data
+	s.mu.Unlock()
+	writeJSON(w, map[string]any{"Key": c.String(), "Size": len(data)})
+}
+
+// handleDagImport implements /api/v0/dag/import: every block is verified
+// against its CID and each header root is pinned.
+func (s *Server) handleDagImport(w http.ResponseWriter, r *http.Request) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	part, err := mr.NextPart()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "file argument 'path' is required")
+		return
+	}
+	name := partFileName(part)
+	cr, err := car.NewReader(part)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var blocks []cid.Block
+	for {
+		b, err := cr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			err = b.CID.Verify(b.Data)
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		blocks = append(blocks, b)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var size int64
+	for _, b := range blocks {
+		size += int64(len(b.Data))
+	}
+	if s.used()+size > s.dataLimit {
+		writeError(w, http.StatusPaymentRequired, "data limit exceeded")
+		return
+	}
+	for _, b := range blocks {
+		s.blocks[blockKey(b.CID)] = b.Data
+	}
+	w.Header().Set("Content-Type", "application/json")
+	for _, root := range cr.Header().Roots {
+		var msg string
+		if n, err := s.contentSize(root); err != nil {
+			msg = err.Error()
+		} else {
+			s.addRecord(name, root.String(), n)
+		}
+		writeJSON(w, map[string]any{"Root": map[string]any{
+			"Cid":         map[string]string{"/": root.String()},
+			"PinErrorMsg": msg,
+		}})
+	}
+}
+
+// readFilePart returns the contents of the first part of a multipart body.
+func readFilePart(r *http.Request) ([]byte, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	part, err := mr.NextPart()
+	if err != nil {
+		return nil, errors.New("file argument 'data' is required")
+	}
+	return io.ReadAll(part)
+}
+
+// node returns the stored block for c. s.mu must be held.
+func (s *Server) node(c cid.CID) ([]byte, error) {
+	b, ok := s.blocks[blockKey(c)]
+	if !ok {
+		return nil, fmt.Errorf("block %s not found", c)
+	}
+	return b, nil
+}
+
+// contentSize returns the number of content bytes below c: file bytes for
+// files, the sum over entries for directories. s.mu must be held.
+func (s *Server) contentSize(c cid.CID) (int64, error) {
+	data, err := s.node(c)
+	if err != nil {
+		return 0, err
+	}
+	if c.Codec != unixfs.CodecDagPB {
+		return int64(len(data)), nil
+	}
+	n, d, err := decodeUnixFS(data)
+	if err != nil {
+		return 0, err
+	}
+	switch d.Type {
+	case unixfs.TypeFile, unixfs.TypeRaw:
+		return int64(d.FileSize), nil
+	case unixfs.TypeDirectory, unixfs.TypeHAMTShard:
+		var total int64
+		for _, l := range n.Links {
+			sz, err := s.contentSize(l.CID)
+			if err != nil {
+				return 0, err
+			}
+			total += sz
+		}
+		return total, nil
+	}
+	return int64(len(d.Data)), nil
+}
+
+// readFile reassembles the content of the file DAG rooted at c. s.mu must
+// be held.
+func (s *Server) readFile(c cid.CID, dst []byte) ([]byte, error) {
+	data, err := s.node(c)
+	if err != nil {
+		return nil, err
+	}
+	if c.Codec != unixfs.CodecDagPB {
+		return append(dst, data...), nil
+	}
+	n, d, err := decodeUnixFS(data)
+	if err != nil {
+		return nil, err
+	}
+	switch d.Type {
+	case unixfs.TypeFile, unixfs.TypeRaw, unixfs.TypeSymlink:
+	default:
+		return nil, fmt.Errorf("%s is not a file", c)
+	}
+	dst = append(dst, d.Data...)
+	for _, l := range n.Links {
+		if dst, err = s.readFile(l.CID, dst); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+func decodeUnixFS(b []byte) (unixfs.Node, unixfs.Data, error) {
+	n, err := unixfs.DecodeNode(b)
+	if err != nil {
+		return unixfs.Node{}, unixfs.Data{}, err
+	}
+	d, err := unixfs.DecodeData(n.Data)
+	return n, d, err
+}
+
+// blockKey indexes blocks by multihash, as kubo does, so CIDv0 and CIDv1
+// forms of a CID find the same block.
+func blockKey(c cid.CID) string { return string(c.Hash) }
@@ -1,8 +1,11 @@
 package lighthouse
 
 import (
+	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
 )
 
 type Option func(*Client)
@@ -11,6 +14,13 @@ func WithAPIKey(key string) Option {
 	return func(c *Client) { c.cfg.APIKey = key }
 }
 
+// WithCredentials looks up the API key through p on every request, taking
+// precedence over WithAPIKey. See the credentials package for env, file,
+// chained and rotating providers.
+func WithCredentials(p CredentialsProvider) Option {
+	return func(c *Client) { c.creds = p }
+}
+
 func WithHosts(api, upload, gateway string) Option {
 	return func(c *Client) {
 		c.cfg.Hosts.API = api
@@ -30,3 +40,40 @@ func WithUserAgent(ua string) Option {
 func WithTimeout(d time.Duration) Option {
 	return func(c *Client) { c.cfg.HTTPTimeout = d }
 }
+
+// WithRetryPolicy replaces the default retry policy. Use NoRetry() to disable
+// retries entirely.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) { c.retry = p }
+}
+
+// WithRateLimit throttles requests client-side, per host, across every
+// service sharing this Client. Callers block (honouring their context) until
+// a token is available. A zero RateLimit leaves that host unlimited.
+func WithRateLimit(api, upload, gateway RateLimit) Option {
+	return func(c *Client) { c.rate = rateLimits{api: api, upload: upload, gateway: gateway} }
+}
+
+// WithMiddleware appends middleware to the request chain. Middleware
+// registered first runs outermost.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) { c.middleware = append(c.middleware, mw...) }
+}
+
+// WithTracer emits a span per service call (e.g. "files.List",
+// "storage.UploadReader") to t. See the lhotel module for OpenTelemetry.
+func WithTracer(t telemetry.Tracer) Option {
+	return func(c *Client) { c.tracer = t }
+}
+
+// WithMeter records operation latency, uploaded bytes and retries to m.
+func WithMeter(m telemetry.Meter) Option {
+	return func(c *Client) { c.meter = m }
+}
+
+// WithLogger logs each request (method, redacted URL, status, duration),
+// retry decisions and service operations at debug level. Credentials in the
+// Authorization header and query string are always redacted.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *Client) { c.logger = l }
+}
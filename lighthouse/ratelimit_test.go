@@ -0,0 +1,70 @@
+package lighthouse_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+)
+
+// Requests are throttled per host: API calls queue at the API rate while an
+// unlimited gateway on another host is not held up.
+func TestRateLimitPerHost(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	gw := lighthousetest.NewServer(t)
+	res, err := gw.Client().Storage().UploadText(ctx, "a.txt", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := srv.Client(
+		lighthouse.WithHosts(srv.URL, srv.URL, gw.URL),
+		lighthouse.WithRateLimit(lighthouse.RateLimit{RPS: 20, Burst: 1}, lighthouse.RateLimit{}, lighthouse.RateLimit{}),
+	)
+
+	run := func(n int, call func() error) time.Duration {
+		start := time.Now()
+		var wg sync.WaitGroup
+		for range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := call(); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+		return time.Since(start)
+	}
+
+	// Five API calls at 20/s with a burst of one take at least 4 × 50ms.
+	if d := run(5, func() error { _, err := client.User().Usage(ctx); return err }); d < 180*time.Millisecond {
+		t.Errorf("5 API calls took %v, want them queued at 20/s", d)
+	}
+	if d := run(5, func() error {
+		rc, _, err := client.Gateway().Get(ctx, res.Hash, "")
+		if err == nil {
+			_, err = io.Copy(io.Discard, rc)
+			rc.Close()
+		}
+		return err
+	}); d > 150*time.Millisecond {
+		t.Errorf("5 gateway calls took %v, want them unthrottled", d)
+	}
+
+	// A caller whose context ends while queued gets ctx.Err() back.
+	if _, err := client.User().Usage(ctx); err != nil {
+		t.Fatal(err)
+	}
+	wait, cancel := context.WithCancel(ctx)
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := client.User().Usage(wait); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
@@ -1,6 +1,12 @@
 package schema
 
-import "io"
+import (
+	"io"
+	"strings"
+	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/filecoin"
+)
 
 type UploadResult struct {
 	Name string `json:"Name"`
@@ -8,9 +14,17 @@ type UploadResult struct {
 	Size string `json:"Size"`
 }
 
+// DirectoryUploadResult is returned by UploadDirectory. Root is the directory
+// node; each entry in Files has Name set to its slash-separated path relative
+// to Root.
+type DirectoryUploadResult struct {
+	Root  UploadResult
+	Files []UploadResult
+}
+
 type Progress struct {
 	Uploaded int64
-	Total    int64
+	Total    int64 // -1 while the upload's size is unknown
 }
 
 type IPNSPublishResponse struct {
@@ -69,6 +83,132 @@ type DealStatus struct {
 	Content            int64  `json:"content"`
 }
 
+// StartTime returns when the deal's mainnet start epoch begins, or the zero
+// time if the deal has no start epoch yet. Use StartTimeOn for other networks.
+func (d DealStatus) StartTime() time.Time { return d.StartTimeOn(filecoin.Mainnet) }
+
+// StartTimeOn is StartTime for a deal made on network n.
+func (d DealStatus) StartTimeOn(n filecoin.Network) time.Time {
+	if d.StartEpoch <= 0 {
+		return time.Time{}
+	}
+	return n.EpochTime(d.StartEpoch)
+}
+
+// ExpiresAt returns when the deal's mainnet end epoch is reached, or the
+// zero time if the deal has no end epoch yet.
+func (d DealStatus) ExpiresAt() time.Time { return d.ExpiresAtOn(filecoin.Mainnet) }
+
+// ExpiresAtOn is ExpiresAt for a deal made on network n.
+func (d DealStatus) ExpiresAtOn(n filecoin.Network) time.Time {
+	if d.EndEpoch <= 0 {
+		return time.Time{}
+	}
+	return n.EpochTime(d.EndEpoch)
+}
+
+// Remaining returns the time left until the mainnet deal expires: zero once
+// it has expired or if it has no end epoch yet.
+func (d DealStatus) Remaining() time.Duration { return d.RemainingOn(filecoin.Mainnet) }
+
+// RemainingOn is Remaining for a deal made on network n.
+func (d DealStatus) RemainingOn(n filecoin.Network) time.Duration {
+	exp := d.ExpiresAtOn(n)
+	if exp.IsZero() {
+		return 0
+	}
+	return max(time.Until(exp), 0)
+}
+
+// DealPhase orders the stages a Filecoin deal goes through.
+type DealPhase int
+
+const (
+	DealQueued DealPhase = iota
+	DealAggregated
+	DealPublished
+	DealActive
+)
+
+func (p DealPhase) String() string {
+	switch p {
+	case DealQueued:
+		return "queued"
+	case DealAggregated:
+		return "aggregated"
+	case DealPublished:
+		return "published"
+	case DealActive:
+		return "active"
+	}
+	return "unknown"
+}
+
+// activeStatuses are the dealStatus values, compared case-insensitively,
+// of a deal that is sealed and proving on chain.
+var activeStatuses = map[string]bool{
+	"active":            true,
+	"storagedealactive": true,
+}
+
+// Phase infers the deal's stage from the fields Lighthouse fills in as it
+// progresses: a piece CID once aggregated, a chain deal ID or publish
+// message once published, and an "Active" status once sealed.
+func (d DealStatus) Phase() DealPhase {
+	switch {
+	case activeStatuses[strings.ToLower(strings.TrimSpace(d.DealStatus))]:
+		return DealActive
+	case d.ChainDealID > 0 || d.DealID > 0 || d.PublishCID != "":
+		return DealPublished
+	case d.PieceCID != "" || d.AggregateIn != "":
+		return DealAggregated
+	}
+	return DealQueued
+}
+
+// DealUpdate is one observation made by Deals().Watch. Phase is the stage
+// reached by at least the requested number of replicas.
+type DealUpdate struct {
+	CID            string
+	Phase          DealPhase
+	ActiveReplicas int
+	Deals          []DealStatus
+	Time           time.Time
+}
+
+type WatchOption func(*WatchOptions)
+
+type WatchOptions struct {
+	Replicas     int
+	Timeout      time.Duration
+	PollInterval time.Duration
+	MaxInterval  time.Duration
+}
+
+func DefaultWatchOptions() *WatchOptions {
+	return &WatchOptions{
+		Replicas:     1,
+		PollInterval: 30 * time.Second,
+		MaxInterval:  10 * time.Minute,
+	}
+}
+
+// WithReplicas waits until n deals are active.
+func WithReplicas(n int) WatchOption {
+	return func(o *WatchOptions) { o.Replicas = n }
+}
+
+// WithWatchTimeout gives up after d.
+func WithWatchTimeout(d time.Duration) WatchOption {
+	return func(o *WatchOptions) { o.Timeout = d }
+}
+
+// WithPollInterval sets the first polling interval and the ceiling it backs
+// off to while nothing changes. A non-positive initial keeps the default.
+func WithPollInterval(initial, max time.Duration) WatchOption {
+	return func(o *WatchOptions) { o.PollInterval, o.MaxInterval = initial, max }
+}
+
 type DealStatusResponse struct {
 	Data []DealStatus `json:"data"`
 }
@@ -78,6 +218,11 @@ type Usage struct {
 	DataUsed  int64 `json:"dataUsed"`
 }
 
+// Remaining returns the bytes still available, never less than zero.
+func (u Usage) Remaining() int64 {
+	return max(u.DataLimit-u.DataUsed, 0)
+}
+
 type IPNSKeyResponse struct {
 	IPNSName string `json:"ipnsName"`
 	IPNSId   string `json:"ipnsId"`
@@ -100,6 +245,19 @@ type IPNSRemoveResponse struct {
 	Keys []IPNSKey `json:"Keys"`
 }
 
+// ContentInfo describes content fetched from the gateway. Size is the
+// length of the returned body and, for ranged reads, Offset is where it
+// starts and TotalSize the length of the whole object. Sizes are -1 when the
+// gateway did not report them.
+type ContentInfo struct {
+	Path        string
+	Size        int64
+	Offset      int64
+	TotalSize   int64
+	ContentType string
+	ETag        string
+}
+
 type Reader = io.Reader
 
 type UploadOption func(*UploadOptions)
@@ -113,8 +271,29 @@ type UploadOptions struct {
 	Progress   io.Writer
 	EncryptKey []byte
 	OnProgress ProgressCallback
+	VerifyCID  bool
+	CheckQuota bool
+
+	// Resumable uploads only: where chunk progress is persisted
+	// (default: <user cache dir>/lighthouse-go-sdk/uploads).
+	StateDir string
+
+	// Directory uploads only.
+	Include     []string
+	Exclude     []string
+	Symlinks    SymlinkPolicy
+	WrapWithDir bool
 }
 
+// SymlinkPolicy decides how UploadDirectory treats symbolic links.
+type SymlinkPolicy int
+
+const (
+	SymlinkSkip   SymlinkPolicy = iota // ignore links (default)
+	SymlinkFollow                      // upload the target's content
+	SymlinkError                       // fail the upload
+)
+
 func DefaultUploadOptions() *UploadOptions {
 	return &UploadOptions{
 		ChunkSize:  8 << 20,
@@ -128,8 +307,12 @@ func DefaultUploadOptions() *UploadOptions {
 	}
 }
 
+// Indeterminate reports whether the total size is unknown, in which case
+// Percent is always 0.
+func (p Progress) Indeterminate() bool { return p.Total < 0 }
+
 func (p Progress) Percent() float64 {
-	if p.Total == 0 {
+	if p.Total <= 0 {
 		return 0
 	}
 	return float64(p.Uploaded) * 100.0 / float64(p.Total)
@@ -140,6 +323,100 @@ type ProgressCallback func(Progress)
 func WithMimeType(mt string) UploadOption { return func(o *UploadOptions) { o.MimeType = mt } }
 func WithPin() UploadOption               { return func(o *UploadOptions) { o.Pin = true } }
 func WithPrivate() UploadOption           { return func(o *UploadOptions) { o.Public = false } }
+
+// WithVerifyCID computes the CID locally while uploading and fails the
+// upload if the server reports a different one.
+func WithVerifyCID() UploadOption { return func(o *UploadOptions) { o.VerifyCID = true } }
+
+// WithQuotaCheck fetches the account usage before uploading and refuses to
+// start, with ErrQuotaExceeded, if the upload would not fit.
+func WithQuotaCheck() UploadOption {
+	return func(o *UploadOptions) { o.CheckQuota = true }
+}
+
+// WithChunkSize sets the chunk size used by resumable uploads.
+func WithChunkSize(n int) UploadOption { return func(o *UploadOptions) { o.ChunkSize = n } }
+
+// WithStateDir sets where resumable uploads keep their progress files.
+func WithStateDir(dir string) UploadOption { return func(o *UploadOptions) { o.StateDir = dir } }
+
+// WithEncryptKey encrypts content client-side with a 32-byte key before it
+// is sent (see the encryption package for the format and decryption).
+func WithEncryptKey(key []byte) UploadOption {
+	return func(o *UploadOptions) { o.EncryptKey = key }
+}
+
 func WithProgress(cb ProgressCallback) UploadOption {
 	return func(o *UploadOptions) { o.OnProgress = cb }
 }
+
+// WithInclude limits a directory upload to files matching any of the glob
+// patterns (path.Match syntax). Patterns without a slash match the base name;
+// others match the path relative to the directory root.
+func WithInclude(patterns ...string) UploadOption {
+	return func(o *UploadOptions) { o.Include = append(o.Include, patterns...) }
+}
+
+// WithExclude skips files and directories matching any of the glob patterns,
+// using the same matching rules as WithInclude. Excluded directories are not
+// descended into.
+func WithExclude(patterns ...string) UploadOption {
+	return func(o *UploadOptions) { o.Exclude = append(o.Exclude, patterns...) }
+}
+
+func WithSymlinks(p SymlinkPolicy) UploadOption {
+	return func(o *UploadOptions) { o.Symlinks = p }
+}
+
+// WithWrapDirectory wraps the uploaded directory in an extra parent node, so
+// the root CID resolves <cid>/<dirname>/... instead of <cid>/....
+func WithWrapDirectory() UploadOption {
+	return func(o *UploadOptions) { o.WrapWithDir = true }
+}
+
+type DownloadOption func(*DownloadOptions)
+
+type DownloadOptions struct {
+	DecryptKey []byte
+	Verify     bool
+	OnProgress ProgressCallback
+}
+
+func DefaultDownloadOptions() *DownloadOptions {
+	return &DownloadOptions{}
+}
+
+// WithDecryptKey decrypts content uploaded with WithEncryptKey.
+func WithDecryptKey(key []byte) DownloadOption {
+	return func(o *DownloadOptions) { o.DecryptKey = key }
+}
+
+// WithVerifiedDownload fetches content as a CAR and checks every block
+// against the requested CID instead of trusting the gateway's bytes.
+func WithVerifiedDownload() DownloadOption {
+	return func(o *DownloadOptions) { o.Verify = true }
+}
+
+func WithDownloadProgress(cb ProgressCallback) DownloadOption {
+	return func(o *DownloadOptions) { o.OnProgress = cb }
+}
+
+type ListOption func(*ListOptions)
+
+// ListOptions tune paginated listings. There is no page size: the
+// files_uploaded endpoint takes only the lastKey cursor and always returns
+// pages of the size the server picks. Use lighthouse.Collect's max, or break
+// out of Files().All, to bound how much is read.
+type ListOptions struct {
+	Prefetch int
+}
+
+func DefaultListOptions() *ListOptions {
+	return &ListOptions{}
+}
+
+// WithPrefetch fetches up to n pages ahead concurrently while the caller
+// consumes the current one.
+func WithPrefetch(n int) ListOption {
+	return func(o *ListOptions) { o.Prefetch = n }
+}
@@ -0,0 +1,53 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/filecoin"
+)
+
+func TestDealPhase(t *testing.T) {
+	for _, tc := range []struct {
+		d    DealStatus
+		want DealPhase
+	}{
+		{DealStatus{}, DealQueued},
+		{DealStatus{PieceCID: "baga"}, DealAggregated},
+		{DealStatus{PieceCID: "baga", ChainDealID: 7, DealStatus: "Sealing"}, DealPublished},
+		{DealStatus{ChainDealID: 7, DealStatus: "Active"}, DealActive},
+		{DealStatus{ChainDealID: 7, DealStatus: "StorageDealActive"}, DealActive},
+		{DealStatus{ChainDealID: 7, DealStatus: "Inactive"}, DealPublished},
+		{DealStatus{PieceCID: "baga", DealStatus: "deactivated"}, DealAggregated},
+	} {
+		if got := tc.d.Phase(); got != tc.want {
+			t.Errorf("Phase(%+v) = %s, want %s", tc.d, got, tc.want)
+		}
+	}
+}
+
+func TestDealTimesOnNetwork(t *testing.T) {
+	d := DealStatus{StartEpoch: 100, EndEpoch: 200}
+	for _, n := range []filecoin.Network{filecoin.Mainnet, filecoin.Calibnet} {
+		if got, want := d.StartTimeOn(n), n.Genesis.Add(100*filecoin.EpochDuration); !got.Equal(want) {
+			t.Errorf("%s: StartTimeOn = %s, want %s", n.Name, got, want)
+		}
+		if got, want := d.ExpiresAtOn(n), n.Genesis.Add(200*filecoin.EpochDuration); !got.Equal(want) {
+			t.Errorf("%s: ExpiresAtOn = %s, want %s", n.Name, got, want)
+		}
+	}
+	if !d.ExpiresAt().Equal(d.ExpiresAtOn(filecoin.Mainnet)) {
+		t.Error("ExpiresAt is not the mainnet time")
+	}
+
+	future := DealStatus{EndEpoch: filecoin.Calibnet.EpochAt(time.Now().Add(48 * time.Hour))}
+	if r := future.RemainingOn(filecoin.Calibnet); r < 47*time.Hour || r > 49*time.Hour {
+		t.Errorf("RemainingOn(calibnet) = %s, want about 48h", r)
+	}
+	if r := (DealStatus{}).RemainingOn(filecoin.Calibnet); r != 0 {
+		t.Errorf("no end epoch: RemainingOn = %s, want 0", r)
+	}
+	if !(DealStatus{}).StartTimeOn(filecoin.Calibnet).IsZero() {
+		t.Error("no start epoch: StartTimeOn is not zero")
+	}
+}
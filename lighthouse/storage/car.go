@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/car"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
+)
+
+// UploadCAR imports a pre-built CARv1/CARv2 file (see the car package). The
+// archive's single root is pinned as-is, so the returned Hash equals the
+// header root and the PayloadCID of the deals made for it.
+func (s *Service) UploadCAR(ctx context.Context, path string, opts ...schema.UploadOption) (_ *schema.UploadResult, err error) {
+	ctx, op := s.h.StartOp(ctx, "storage.UploadCAR")
+	defer func() { op.End(err) }()
+
+	o := schema.DefaultUploadOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.EncryptKey != nil {
+		return nil, errors.New("encryption is not supported for CAR uploads")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if o.CheckQuota {
+		if err := s.checkQuota(ctx, stat.Size()); err != nil {
+			return nil, err
+		}
+	}
+
+	cr, err := car.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	roots := cr.Header().Roots
+	if len(roots) != 1 {
+		return nil, fmt.Errorf("car must have exactly one root, got %d", len(roots))
+	}
+	root := roots[0]
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	op.SetAttributes(telemetry.String(telemetry.AttrCID, root.String()))
+
+	q := url.Values{"pin-roots": {"true"}}
+	u := s.cfg.Hosts.Upload + "/api/v0/dag/import?" + q.Encode()
+	res, totalSize, err := s.postFile(ctx, u, filepath.Base(path), stat.Size(), f, o, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	imported, err := decodeImportRoots(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, c := range imported {
+		if parsed, err := cid.Parse(c); err == nil && parsed.Equal(root) {
+			found = true
+		}
+	}
+	if !found {
+		// An empty response is no better than a different root: nothing
+		// confirms the archive was pinned under the CID we return.
+		return nil, fmt.Errorf("%w: car root %s not confirmed, server reported %v", ErrCIDMismatch, root, imported)
+	}
+	op.AddUploaded(stat.Size())
+
+	if o.OnProgress != nil {
+		o.OnProgress(schema.Progress{Uploaded: totalSize, Total: totalSize})
+	}
+	return &schema.UploadResult{
+		Name: filepath.Base(path),
+		Hash: root.String(),
+		Size: strconv.FormatInt(stat.Size(), 10),
+	}, nil
+}
+
+// decodeImportRoots reads the newline-delimited dag/import response.
+func decodeImportRoots(r io.Reader) ([]string, error) {
+	var roots []string
+	dec := json.NewDecoder(r)
+	for {
+		var line struct {
+			Root *struct {
+				Cid struct {
+					Link string `json:"/"`
+				} `json:"Cid"`
+				PinErrorMsg string `json:"PinErrorMsg"`
+			} `json:"Root"`
+		}
+		if err := dec.Decode(&line); err == io.EOF {
+			return roots, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if line.Root == nil {
+			continue
+		}
+		if line.Root.PinErrorMsg != "" {
+			return nil, errors.New("pinning " + line.Root.Cid.Link + ": " + line.Root.PinErrorMsg)
+		}
+		roots = append(roots, line.Root.Cid.Link)
+	}
+}
@@ -0,0 +1,118 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/car"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+)
+
+// createCAR packs a small site into a CAR of the given version.
+func createCAR(t *testing.T, version int) (string, cid.CID) {
+	t.Helper()
+	root := writeTree(t, map[string]string{"index.html": "<h1>home</h1>", "css/style.css": "body{}"}, nil)
+	dst := filepath.Join(t.TempDir(), "site.car")
+	c, err := car.Create(dst, root, car.Options{Version: version})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dst, c
+}
+
+// importRoot rewrites the root CIDs that /api/v0/dag/import reports.
+type importRoot func(body string) string
+
+func (f importRoot) RoundTrip(r *http.Request) (*http.Response, error) {
+	res, err := http.DefaultTransport.RoundTrip(r)
+	if err != nil || r.URL.Path != "/api/v0/dag/import" {
+		return res, err
+	}
+	b, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	b = []byte(f(string(b)))
+	res.Body = io.NopCloser(bytes.NewReader(b))
+	res.ContentLength = int64(len(b))
+	return res, nil
+}
+
+func TestUploadCAR(t *testing.T) {
+	ctx := context.Background()
+	for _, version := range []int{1, 2} {
+		srv := lighthousetest.NewServer(t)
+		path, root := createCAR(t, version)
+
+		res, err := srv.Client().Storage().UploadCAR(ctx, path)
+		if err != nil {
+			t.Fatalf("CARv%d: %v", version, err)
+		}
+		if res.Hash != root.String() || res.Name != "site.car" {
+			t.Errorf("CARv%d: result %+v, want root %s", version, res, root)
+		}
+		if files := srv.Files(); len(files) != 1 || files[0].CID != root.String() {
+			t.Errorf("CARv%d: Files() = %+v, want the root pinned", version, files)
+		}
+		rc, _, err := srv.Client().Gateway().Get(ctx, res.Hash, "css/style.css")
+		if err != nil {
+			t.Fatalf("CARv%d: %v", version, err)
+		}
+		got, _ := io.ReadAll(rc)
+		rc.Close()
+		if string(got) != "body{}" {
+			t.Errorf("CARv%d: css/style.css = %q", version, got)
+		}
+	}
+}
+
+// The root the server confirms must be the archive's own; a different or
+// missing confirmation fails the upload.
+func TestUploadCARRootMismatch(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	path, root := createCAR(t, 1)
+	other, _ := cid.Compute(strings.NewReader("other"))
+
+	for name, rewrite := range map[string]importRoot{
+		"different": func(body string) string { return strings.ReplaceAll(body, root.String(), other.String()) },
+		"empty":     func(string) string { return "" },
+	} {
+		client := srv.Client(lighthouse.WithHTTPClient(&http.Client{Transport: rewrite}))
+		_, err := client.Storage().UploadCAR(ctx, path)
+		if !errors.Is(err, lighthouse.ErrCIDMismatch) {
+			t.Errorf("%s root: err = %v, want ErrCIDMismatch", name, err)
+		}
+	}
+}
+
+// A root whose blocks are not in the archive cannot be pinned; the server's
+// PinErrorMsg is returned.
+func TestUploadCARPinError(t *testing.T) {
+	srv := lighthousetest.NewServer(t)
+	root, _ := cid.Compute(strings.NewReader("not included"))
+	path := filepath.Join(t.TempDir(), "empty.car")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := car.NewWriter(f, root); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	_, err = srv.Client().Storage().UploadCAR(context.Background(), path)
+	if err == nil || !strings.Contains(err.Error(), "pinning "+root.String()) {
+		t.Errorf("err = %v, want the pin error for %s", err, root)
+	}
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+)
+
+// ErrCIDMismatch is returned by uploads made with schema.WithVerifyCID when
+// the server's CID differs from the one computed locally.
+var ErrCIDMismatch = errors.New("uploaded CID does not match local CID")
+
+// ComputeCID returns the CID r would be stored under by a default upload,
+// without sending anything.
+func ComputeCID(r io.Reader) (string, error) {
+	c, err := cid.Compute(r)
+	if err != nil {
+		return "", err
+	}
+	return c.String(), nil
+}
+
+func verifyCID(b *cid.Builder, got string) error {
+	want, err := b.Sum()
+	if err != nil {
+		return err
+	}
+	parsed, err := cid.Parse(got)
+	if err != nil || !parsed.Equal(want.CID) {
+		return fmt.Errorf("%w: local %s, server %s", ErrCIDMismatch, want.CID, got)
+	}
+	return nil
+}
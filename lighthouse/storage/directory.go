@@ -0,0 +1,293 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/encryption"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/httpx"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
+)
+
+// dirEntry is a regular file selected for a directory upload.
+type dirEntry struct {
+	rel  string // slash-separated, relative to the upload root
+	path string // path to open on disk
+	size int64
+}
+
+// UploadDirectory uploads every file below root in one multipart request,
+// preserving relative paths. Empty directories are not uploaded.
+func (s *Service) UploadDirectory(ctx context.Context, root string, opts ...schema.UploadOption) (_ *schema.DirectoryUploadResult, err error) {
+	ctx, op := s.h.StartOp(ctx, "storage.UploadDirectory")
+	defer func() { op.End(err) }()
+
+	o := schema.DefaultUploadOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	for _, p := range append(append([]string{}, o.Include...), o.Exclude...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+
+	root = filepath.Clean(root)
+	entries, err := collectDir(root, o)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no files to upload under %s", root)
+	}
+
+	if o.EncryptKey != nil && len(o.EncryptKey) != encryption.KeySize {
+		return nil, encryption.ErrKeySize
+	}
+	var total int64
+	for _, e := range entries {
+		if o.EncryptKey != nil {
+			total += encryption.EncryptedSize(e.size)
+		} else {
+			total += e.size
+		}
+	}
+	if o.CheckQuota {
+		if err := s.checkQuota(ctx, total); err != nil {
+			return nil, err
+		}
+	}
+	base := filepath.Base(root)
+
+	s.h.Logger().DebugContext(ctx, "lighthouse directory upload starting",
+		slog.String("root", root),
+		slog.Int("files", len(entries)),
+		slog.Int64("size", total))
+
+	var body *multipartBody
+	build := func(attempt int) (*http.Request, error) {
+		body.abort()
+		var uploaded int64
+		body = newMultipartBody(func(mw *multipart.Writer) error {
+			for _, e := range entries {
+				if err := writeDirEntry(ctx, mw, base+"/"+e.rel, e, o.EncryptKey, func(n int) {
+					u := atomic.AddInt64(&uploaded, int64(n))
+					if o.OnProgress != nil {
+						o.OnProgress(schema.Progress{Uploaded: u, Total: total})
+					}
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+		u := s.cfg.Hosts.Upload + "/api/v0/add?cid-version=1&wrap-with-directory=" + strconv.FormatBool(o.WrapWithDir)
+		req, err := http.NewRequestWithContext(ctx, "POST", u, body.pr)
+		if err != nil {
+			body.abort()
+			return nil, err
+		}
+		req.Header.Set("Content-Type", body.contentType)
+		return req, nil
+	}
+
+	res, err := s.send(ctx, true, build)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if err := httpx.CheckResponse(res); err != nil {
+		return nil, err
+	}
+
+	out, err := decodeDirectoryResult(res.Body, base, o.WrapWithDir, entries)
+	if err != nil {
+		return nil, err
+	}
+	op.SetAttributes(telemetry.String(telemetry.AttrCID, out.Root.Hash))
+	op.AddUploaded(total)
+
+	if o.OnProgress != nil {
+		o.OnProgress(schema.Progress{Uploaded: total, Total: total})
+	}
+	return out, nil
+}
+
+// writeDirEntry adds one file to the form, encrypting it when key is set.
+func writeDirEntry(ctx context.Context, mw *multipart.Writer, name string, e dirEntry, key []byte, onWrite func(int)) error {
+	f, err := os.Open(e.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var src io.Reader = f
+	if key != nil {
+		if src, err = encryption.NewEncryptReader(f, key); err != nil {
+			return err
+		}
+	}
+	fw, err := createFilePart(mw, name)
+	if err != nil {
+		return err
+	}
+	return copyContext(ctx, countingWriter{w: fw, fn: onWrite}, src)
+}
+
+type countingWriter struct {
+	w  io.Writer
+	fn func(int)
+}
+
+func (c countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	if n > 0 {
+		c.fn(n)
+	}
+	return n, err
+}
+
+// decodeDirectoryResult reads the newline-delimited add response and
+// separates the root directory from the uploaded files.
+func decodeDirectoryResult(r io.Reader, base string, wrapped bool, entries []dirEntry) (*schema.DirectoryUploadResult, error) {
+	want := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		want[base+"/"+e.rel] = true
+	}
+
+	var (
+		out     schema.DirectoryUploadResult
+		last    schema.UploadResult
+		gotRoot bool
+	)
+	dec := json.NewDecoder(r)
+	for {
+		var item schema.UploadResult
+		if err := dec.Decode(&item); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		last = item
+
+		switch {
+		case want[item.Name]:
+			item.Name = strings.TrimPrefix(item.Name, base+"/")
+			out.Files = append(out.Files, item)
+		case wrapped && item.Name == "", !wrapped && item.Name == base:
+			out.Root = item
+			gotRoot = true
+		}
+	}
+	if !gotRoot {
+		if last.Hash == "" {
+			return nil, errors.New("empty response from upload endpoint")
+		}
+		// The directory node is always reported last.
+		out.Root = last
+	}
+	return &out, nil
+}
+
+// collectDir walks root applying the include/exclude filters and symlink policy.
+func collectDir(root string, o *schema.UploadOptions) ([]dirEntry, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	var entries []dirEntry
+	visited := map[string]bool{}
+
+	var walk func(dir, rel string) error
+	walk = func(dir, rel string) error {
+		real, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			return err
+		}
+		if visited[real] {
+			return nil // symlink cycle
+		}
+		visited[real] = true
+		defer delete(visited, real)
+
+		des, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, de := range des {
+			childRel := path.Join(rel, de.Name())
+			childPath := filepath.Join(dir, de.Name())
+
+			fi, err := de.Info()
+			if err != nil {
+				return err
+			}
+			if fi.Mode()&fs.ModeSymlink != 0 {
+				switch o.Symlinks {
+				case schema.SymlinkSkip:
+					continue
+				case schema.SymlinkError:
+					return fmt.Errorf("symlink not allowed: %s", childPath)
+				}
+				if fi, err = os.Stat(childPath); err != nil {
+					return err
+				}
+			}
+
+			if matchAny(o.Exclude, childRel) {
+				continue
+			}
+			switch {
+			case fi.IsDir():
+				if err := walk(childPath, childRel); err != nil {
+					return err
+				}
+			case fi.Mode().IsRegular():
+				if len(o.Include) > 0 && !matchAny(o.Include, childRel) {
+					continue
+				}
+				entries = append(entries, dirEntry{rel: childRel, path: childPath, size: fi.Size()})
+			}
+		}
+		return nil
+	}
+	if err := walk(root, ""); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// matchAny reports whether rel matches one of the patterns. Patterns without
+// a slash are matched against the base name only.
+func matchAny(patterns []string, rel string) bool {
+	for _, p := range patterns {
+		target := rel
+		if !strings.Contains(p, "/") {
+			target = path.Base(rel)
+		}
+		if ok, _ := path.Match(p, target); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package storage_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+// writeTree creates files (relative path to content) and symlinks (relative
+// path to target) under a new directory named "site".
+func writeTree(t *testing.T, files, links map[string]string) string {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "site")
+	for rel, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for rel, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, filepath.FromSlash(rel))); err != nil {
+			t.Skipf("symlinks unavailable: %v", err)
+		}
+	}
+	return root
+}
+
+var siteFiles = map[string]string{
+	"index.html":               "<h1>home</h1>",
+	"about.txt":                "about",
+	"build.log":                "log",
+	"docs/guide.txt":           "guide",
+	"docs/debug.log":           "debug",
+	"docs/img/logo.txt":        "logo",
+	"node_modules/pkg/lib.txt": "dependency",
+}
+
+func uploadedNames(t *testing.T, root string, opts ...schema.UploadOption) []string {
+	t.Helper()
+	srv := lighthousetest.NewServer(t)
+	res, err := srv.Client().Storage().UploadDirectory(context.Background(), root, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range res.Files {
+		names = append(names, f.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestUploadDirectoryFilters(t *testing.T) {
+	root := writeTree(t, siteFiles, nil)
+	for _, tc := range []struct {
+		name string
+		opts []schema.UploadOption
+		want []string
+	}{
+		{"all", nil, []string{"about.txt", "build.log", "docs/debug.log", "docs/guide.txt", "docs/img/logo.txt", "index.html", "node_modules/pkg/lib.txt"}},
+		// Patterns without a slash match the base name at any depth.
+		{"include base name", []schema.UploadOption{schema.WithInclude("*.txt")},
+			[]string{"about.txt", "docs/guide.txt", "docs/img/logo.txt", "node_modules/pkg/lib.txt"}},
+		// Patterns with a slash match the whole relative path.
+		{"include path", []schema.UploadOption{schema.WithInclude("docs/*.txt")}, []string{"docs/guide.txt"}},
+		{"exclude base name", []schema.UploadOption{schema.WithExclude("*.log")},
+			[]string{"about.txt", "docs/guide.txt", "docs/img/logo.txt", "index.html", "node_modules/pkg/lib.txt"}},
+		// An excluded directory is pruned along with everything below it.
+		{"exclude directory", []schema.UploadOption{schema.WithExclude("node_modules", "img")},
+			[]string{"about.txt", "build.log", "docs/debug.log", "docs/guide.txt", "index.html"}},
+		{"exclude path", []schema.UploadOption{schema.WithExclude("docs/*")},
+			[]string{"about.txt", "build.log", "index.html", "node_modules/pkg/lib.txt"}},
+		{"include and exclude", []schema.UploadOption{schema.WithInclude("*.txt"), schema.WithExclude("node_modules")},
+			[]string{"about.txt", "docs/guide.txt", "docs/img/logo.txt"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := uploadedNames(t, root, tc.opts...); !slices.Equal(got, tc.want) {
+				t.Errorf("uploaded %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	srv := lighthousetest.NewServer(t)
+	if _, err := srv.Client().Storage().UploadDirectory(context.Background(), root, schema.WithInclude("[")); err == nil {
+		t.Error("malformed pattern: want an error")
+	}
+	if _, err := srv.Client().Storage().UploadDirectory(context.Background(), root, schema.WithInclude("*.none")); err == nil {
+		t.Error("nothing selected: want an error")
+	}
+}
+
+func TestUploadDirectorySymlinks(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"a.txt":     "a",
+		"sub/b.txt": "b",
+	}, map[string]string{
+		"file-link":    "a.txt",
+		"dir-link":     "sub",
+		"sub/loop":     "..",
+		"sub/loop-own": ".",
+	})
+
+	if got, want := uploadedNames(t, root), []string{"a.txt", "sub/b.txt"}; !slices.Equal(got, want) {
+		t.Errorf("SymlinkSkip uploaded %q, want %q", got, want)
+	}
+
+	// Following links includes their targets but stops at cycles.
+	got := uploadedNames(t, root, schema.WithSymlinks(schema.SymlinkFollow))
+	want := []string{"a.txt", "dir-link/b.txt", "file-link", "sub/b.txt"}
+	if !slices.Equal(got, want) {
+		t.Errorf("SymlinkFollow uploaded %q, want %q", got, want)
+	}
+
+	srv := lighthousetest.NewServer(t)
+	_, err := srv.Client().Storage().UploadDirectory(context.Background(), root, schema.WithSymlinks(schema.SymlinkError))
+	if err == nil || !strings.Contains(err.Error(), "symlink not allowed") {
+		t.Errorf("SymlinkError: err = %v", err)
+	}
+}
+
+func TestUploadDirectoryResult(t *testing.T) {
+	ctx := context.Background()
+	root := writeTree(t, map[string]string{
+		"index.html":    "<h1>home</h1>",
+		"css/style.css": "body{}",
+	}, nil)
+
+	for _, wrap := range []bool{false, true} {
+		srv := lighthousetest.NewServer(t)
+		client := srv.Client()
+		var opts []schema.UploadOption
+		prefix := ""
+		if wrap {
+			opts = append(opts, schema.WithWrapDirectory())
+			prefix = "site/"
+		}
+		res, err := client.Storage().UploadDirectory(ctx, root, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wrap && res.Root.Name != "" || !wrap && res.Root.Name != "site" {
+			t.Errorf("wrap=%v: Root.Name = %q", wrap, res.Root.Name)
+		}
+
+		// Each file result carries the CID of its own content, and the
+		// root resolves it at the expected path.
+		if len(res.Files) != 2 {
+			t.Fatalf("wrap=%v: Files = %+v", wrap, res.Files)
+		}
+		for _, f := range res.Files {
+			content := map[string]string{"index.html": "<h1>home</h1>", "css/style.css": "body{}"}[f.Name]
+			want, _ := cid.Compute(strings.NewReader(content))
+			if f.Hash != want.String() {
+				t.Errorf("wrap=%v: %s Hash = %s, want %s", wrap, f.Name, f.Hash, want)
+			}
+			rc, _, err := client.Gateway().Get(ctx, res.Root.Hash, prefix+f.Name)
+			if err != nil {
+				t.Fatalf("wrap=%v: Get %s%s: %v", wrap, prefix, f.Name, err)
+			}
+			got, _ := io.ReadAll(rc)
+			rc.Close()
+			if string(got) != content {
+				t.Errorf("wrap=%v: %s%s = %q, want %q", wrap, prefix, f.Name, got, content)
+			}
+		}
+	}
+}
+
+func TestUploadDirectoryProgress(t *testing.T) {
+	root := writeTree(t, siteFiles, nil)
+	var total int64
+	for _, c := range siteFiles {
+		total += int64(len(c))
+	}
+
+	var got []schema.Progress
+	srv := lighthousetest.NewServer(t)
+	_, err := srv.Client().Storage().UploadDirectory(context.Background(), root,
+		schema.WithProgress(func(p schema.Progress) { got = append(got, p) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) == 0 {
+		t.Fatal("no progress reported")
+	}
+	var prev int64
+	for _, p := range got {
+		if p.Total != total || p.Uploaded < prev || p.Uploaded > total {
+			t.Errorf("progress %+v after %d, want a running count of %d", p, prev, total)
+		}
+		prev = p.Uploaded
+	}
+	if last := got[len(got)-1]; last.Uploaded != total {
+		t.Errorf("final progress %+v, want %d", last, total)
+	}
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+)
+
+// multipartBody streams a multipart form, produced by a writer goroutine,
+// through a pipe.
+type multipartBody struct {
+	pr          *io.PipeReader
+	done        chan struct{}
+	boundary    string
+	contentType string
+}
+
+// newMultipartBody starts write in a goroutine; the form is closed after
+// write returns successfully, and the pipe fails with its error otherwise.
+func newMultipartBody(write func(mw *multipart.Writer) error) *multipartBody {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+
+	b := &multipartBody{
+		pr:          pr,
+		done:        make(chan struct{}),
+		boundary:    mw.Boundary(),
+		contentType: mw.FormDataContentType(),
+	}
+
+	// Goroutine to write multipart data
+	go func() {
+		defer close(b.done)
+		if err := write(mw); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(mw.Close())
+	}()
+	return b
+}
+
+// abort stops the writer goroutine and waits until it no longer touches the
+// source reader, so the source can be safely rewound.
+func (b *multipartBody) abort() {
+	if b == nil {
+		return
+	}
+	b.pr.CloseWithError(io.ErrClosedPipe)
+	<-b.done
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// createFilePart opens a "file" form field; filename may contain slashes to
+// place the file inside a directory of the resulting DAG.
+func createFilePart(mw *multipart.Writer, filename string) (io.Writer, error) {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", `form-data; name="file"; filename="`+quoteEscaper.Replace(filename)+`"`)
+	h.Set("Content-Type", "application/octet-stream")
+	return mw.CreatePart(h)
+}
+
+// copyContext copies src to dst, stopping early when ctx is done.
+func copyContext(ctx context.Context, dst io.Writer, src io.Reader) error {
+	buf := make([]byte, 32*1024)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		n, err := src.Read(buf)
+		if n > 0 {
+			if _, writeErr := dst.Write(buf[:n]); writeErr != nil {
+				return writeErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// send executes build once, or under the client's retry policy when the
+// request body can be regenerated.
+func (s *Service) send(ctx context.Context, rewindable bool, build func(attempt int) (*http.Request, error)) (*http.Response, error) {
+	if rewindable {
+		return s.h.Retry(ctx, build)
+	}
+	req, err := build(0)
+	if err != nil {
+		return nil, err
+	}
+	return s.h.Inject(req)
+}
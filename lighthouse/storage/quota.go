@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/httpx"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/user"
+)
+
+// checkQuota fails with ErrQuotaExceeded when need bytes would not fit in
+// the account's remaining storage.
+func (s *Service) checkQuota(ctx context.Context, need int64) error {
+	u, err := user.New(s.h, s.cfg).Usage(ctx)
+	if err != nil {
+		return fmt.Errorf("quota check: %w", err)
+	}
+	if u.DataLimit <= 0 {
+		return nil
+	}
+	if rem := u.Remaining(); need > rem {
+		return fmt.Errorf("%w: upload needs %d bytes, %d of %d remain", httpx.ErrQuotaExceeded, need, rem, u.DataLimit)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+// An upload that would not fit is refused after the usage lookup, before
+// anything is sent to /api/v0/add.
+func TestQuotaCheckRefusesUpload(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t, lighthousetest.WithDataLimit(100))
+	st := srv.Client().Storage()
+	root := writeTree(t, map[string]string{"a.txt": strings.Repeat("a", 60), "b.txt": strings.Repeat("b", 60)}, nil)
+
+	for name, upload := range map[string]func() error{
+		"text": func() error {
+			_, err := st.UploadText(ctx, "big.txt", strings.Repeat("x", 101), schema.WithQuotaCheck())
+			return err
+		},
+		"directory": func() error {
+			_, err := st.UploadDirectory(ctx, root, schema.WithQuotaCheck())
+			return err
+		},
+	} {
+		if err := upload(); !errors.Is(err, lighthouse.ErrQuotaExceeded) {
+			t.Errorf("%s: err = %v, want ErrQuotaExceeded", name, err)
+		}
+	}
+	if hits := srv.Hits(http.MethodPost, "/api/v0/add"); hits != 0 {
+		t.Errorf("add requests = %d, want none", hits)
+	}
+	if hits := srv.Hits(http.MethodGet, "/api/user/user_data_usage"); hits != 2 {
+		t.Errorf("usage requests = %d, want one per upload", hits)
+	}
+
+	// An upload that fits exactly goes through; then the account is full and
+	// even a stream of unknown size is refused.
+	if _, err := st.UploadText(ctx, "fits.txt", strings.Repeat("x", 100), schema.WithQuotaCheck()); err != nil {
+		t.Fatal(err)
+	}
+	_, err := st.UploadReader(ctx, "stream.txt", -1, strings.NewReader("x"), schema.WithQuotaCheck())
+	if !errors.Is(err, lighthouse.ErrQuotaExceeded) {
+		t.Errorf("unknown size on a full account: err = %v, want ErrQuotaExceeded", err)
+	}
+	if hits := srv.Hits(http.MethodPost, "/api/v0/add"); hits != 1 {
+		t.Errorf("add requests = %d, want only the one that fit", hits)
+	}
+}
+
+// ResumeUpload checks the quota against the bytes not yet uploaded, so a
+// resumed upload fits where starting over would not.
+func TestResumeUploadQuotaRemaining(t *testing.T) {
+	ctx := context.Background()
+	// 10000 bytes in 5 chunks; after two chunks 4096 bytes are used and
+	// 7904 remain: enough for the 5904 left, not for the whole file.
+	srv := lighthousetest.NewServer(t, lighthousetest.WithDataLimit(12000))
+	path := writeTemp(t, bytes.Repeat([]byte("abcdefghij"), 1000))
+	stateDir := t.TempDir()
+	opts := []schema.UploadOption{schema.WithChunkSize(2048), schema.WithStateDir(stateDir), schema.WithQuotaCheck()}
+
+	client := srv.Client(lighthouse.WithHTTPClient(&http.Client{Transport: &failNth{srv: srv, n: 3}}))
+	if _, err := client.Storage().ResumeUpload(ctx, path, opts...); err == nil {
+		t.Fatal("interrupted upload: want an error")
+	}
+	adds := srv.Hits(http.MethodPost, "/api/v0/add")
+
+	fresh := []schema.UploadOption{schema.WithChunkSize(2048), schema.WithStateDir(t.TempDir()), schema.WithQuotaCheck()}
+	if _, err := srv.Client().Storage().ResumeUpload(ctx, path, fresh...); !errors.Is(err, lighthouse.ErrQuotaExceeded) {
+		t.Fatalf("starting over: err = %v, want ErrQuotaExceeded", err)
+	}
+	if hits := srv.Hits(http.MethodPost, "/api/v0/add"); hits != adds {
+		t.Errorf("refused upload sent %d add requests", hits-adds)
+	}
+
+	if _, err := srv.Client().Storage().ResumeUpload(ctx, path, opts...); err != nil {
+		t.Fatalf("resuming: %v", err)
+	}
+	if states, _ := os.ReadDir(stateDir); len(states) != 0 {
+		t.Errorf("state files after completion = %d, want 0", len(states))
+	}
+}
@@ -0,0 +1,335 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/files"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/httpx"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/unixfs"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
+)
+
+// ErrResumeUnsupported is returned by ResumeUpload when the upload node
+// rejects the block/put call that stores the file's root node.
+var ErrResumeUnsupported = errors.New("upload node does not accept block/put; resumable uploads are unavailable")
+
+// resumeState is persisted after every completed chunk of a resumable upload.
+type resumeState struct {
+	Path      string       `json:"path"`
+	Size      int64        `json:"size"`
+	ModTime   time.Time    `json:"modTime"`
+	ChunkSize int64        `json:"chunkSize"`
+	Chunks    []chunkState `json:"chunks"`
+}
+
+type chunkState struct {
+	Index int    `json:"index"`
+	CID   string `json:"cid"`
+	Size  int64  `json:"size"`  // bytes of file content
+	Tsize uint64 `json:"tsize"` // cumulative DAG size reported by the node
+}
+
+// ResumeUpload uploads path in UploadOptions.ChunkSize pieces, recording each
+// finished piece in a state file so that a later call with the same file
+// (same path, size and mtime) continues where the previous one stopped, even
+// across process restarts. State is removed once the upload completes.
+//
+// Lighthouse has no resumable upload endpoint, so this is built from calls
+// that are not meant for it, with three consequences:
+//
+//   - Each chunk is added through /api/v0/add as a file named
+//     "<name>.partNNNNN". Until the upload finishes these parts are listed
+//     by Files().List and count against the quota.
+//   - The chunks are linked by a UnixFS file node built locally and stored
+//     with /api/v0/block/put, which Lighthouse does not document. If the node
+//     answers 404 or 405 the upload fails with ErrResumeUnsupported and the
+//     parts are left in place.
+//   - The returned Hash is a valid file CID but differs from UploadFile's
+//     for the same content, since every chunk is its own subtree.
+//
+// Once the root is pinned it is recorded under the file's name and the part
+// records are deleted; failing to delete them is logged, not returned.
+func (s *Service) ResumeUpload(ctx context.Context, path string, opts ...schema.UploadOption) (_ *schema.UploadResult, err error) {
+	ctx, op := s.h.StartOp(ctx, "storage.ResumeUpload")
+	defer func() { op.End(err) }()
+
+	o := schema.DefaultUploadOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.ChunkSize <= 0 {
+		return nil, fmt.Errorf("invalid chunk size %d", o.ChunkSize)
+	}
+	if o.EncryptKey != nil {
+		// Chunks are separate uploads; their ciphertexts would not form one stream.
+		return nil, errors.New("encryption is not supported for resumable uploads")
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(abs)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	stateFile, err := resumeStatePath(o.StateDir, abs, stat, int64(o.ChunkSize))
+	if err != nil {
+		return nil, err
+	}
+	st := loadResumeState(stateFile, abs, stat, int64(o.ChunkSize))
+
+	size := stat.Size()
+	chunkSize := int64(o.ChunkSize)
+	nChunks := int((size + chunkSize - 1) / chunkSize)
+	if nChunks == 0 {
+		nChunks = 1
+	}
+	done := make(map[int]chunkState, len(st.Chunks))
+	var doneBytes int64
+	for _, c := range st.Chunks {
+		done[c.Index] = c
+		doneBytes += c.Size
+	}
+
+	if o.CheckQuota {
+		// Checked once for what is left; the chunks skip their own check.
+		if err := s.checkQuota(ctx, size-doneBytes); err != nil {
+			return nil, err
+		}
+	}
+
+	s.h.Logger().DebugContext(ctx, "lighthouse resumable upload",
+		slog.String("path", abs),
+		slog.Int("chunks", nChunks),
+		slog.Int("completed", len(done)),
+		slog.String("state", stateFile))
+
+	name := filepath.Base(abs)
+	for i := 0; i < nChunks; i++ {
+		if _, ok := done[i]; ok {
+			continue
+		}
+		off := int64(i) * chunkSize
+		n := min(chunkSize, size-off)
+
+		chunkName := name
+		if nChunks > 1 {
+			chunkName = fmt.Sprintf("%s.part%05d", name, i)
+		}
+		chunkOpts := append([]schema.UploadOption{}, opts...)
+		chunkOpts = append(chunkOpts, func(co *schema.UploadOptions) { co.CheckQuota = false })
+		if o.OnProgress != nil {
+			base := doneBytes
+			chunkOpts = append(chunkOpts, schema.WithProgress(func(p schema.Progress) {
+				var sent int64
+				if p.Total > 0 {
+					sent = n * p.Uploaded / p.Total
+				}
+				o.OnProgress(schema.Progress{Uploaded: base + sent, Total: size})
+			}))
+		}
+
+		res, err := s.UploadReader(ctx, chunkName, n, io.NewSectionReader(f, off, n), chunkOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d/%d: %w", i+1, nChunks, err)
+		}
+		tsize, err := strconv.ParseUint(res.Size, 10, 64)
+		if err != nil {
+			tsize = uint64(n)
+		}
+		st.Chunks = append(st.Chunks, chunkState{Index: i, CID: res.Hash, Size: n, Tsize: tsize})
+		done[i] = st.Chunks[len(st.Chunks)-1]
+		doneBytes += n
+		if err := saveResumeState(stateFile, st); err != nil {
+			return nil, err
+		}
+	}
+
+	children := make([]unixfs.Child, nChunks)
+	for i := range children {
+		c := done[i]
+		id, err := unixfs.Parse(c.CID)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", i, err)
+		}
+		children[i] = unixfs.Child{CID: id, FileSize: uint64(c.Size), Tsize: c.Tsize}
+	}
+	root, blocks := unixfs.Balanced(children, unixfs.DefaultLinksPerBlock)
+	for _, b := range blocks {
+		if err := s.putBlock(ctx, b); err != nil {
+			return nil, err
+		}
+	}
+	if nChunks > 1 {
+		// A single chunk was added under the file's own name and is the root.
+		fsvc := files.New(s.h, s.cfg)
+		if err := fsvc.Pin(ctx, root.CID.String(), name); err != nil {
+			return nil, fmt.Errorf("pinning root: %w", err)
+		}
+		s.removeParts(ctx, fsvc, name, st.Chunks)
+	}
+
+	if err := os.Remove(stateFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	op.SetAttributes(telemetry.String(telemetry.AttrCID, root.CID.String()))
+
+	if o.OnProgress != nil {
+		o.OnProgress(schema.Progress{Uploaded: size, Total: size})
+	}
+	return &schema.UploadResult{
+		Name: name,
+		Hash: root.CID.String(),
+		Size: strconv.FormatUint(root.Tsize, 10),
+	}, nil
+}
+
+// removeParts deletes the file records of the uploaded chunks. Only records
+// matching both a part's name and its CID are touched.
+func (s *Service) removeParts(ctx context.Context, fsvc *files.Service, name string, chunks []chunkState) {
+	parts := make(map[string]string, len(chunks))
+	for _, c := range chunks {
+		parts[fmt.Sprintf("%s.part%05d", name, c.Index)] = c.CID
+	}
+	var ids []string
+	for f, err := range fsvc.All(ctx) {
+		if err != nil {
+			s.h.Logger().WarnContext(ctx, "lighthouse resumable upload: listing parts", slog.String("error", err.Error()))
+			return
+		}
+		if c, ok := parts[f.Name]; ok && c == f.CID && f.ID != "" {
+			ids = append(ids, f.ID)
+		}
+	}
+	for _, id := range ids {
+		if err := fsvc.Delete(ctx, id); err != nil {
+			s.h.Logger().WarnContext(ctx, "lighthouse resumable upload: deleting part",
+				slog.String("id", id), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// putBlock stores an encoded dag-pb node and checks the node agrees on its CID.
+func (s *Service) putBlock(ctx context.Context, b unixfs.Block) error {
+	var body *multipartBody
+	build := func(int) (*http.Request, error) {
+		body.abort()
+		body = newMultipartBody(func(mw *multipart.Writer) error {
+			fw, err := createFilePart(mw, "block")
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(fw, bytes.NewReader(b.Data))
+			return err
+		})
+		q := url.Values{"cid-codec": {"dag-pb"}, "mhtype": {"sha2-256"}, "pin": {"true"}}
+		req, err := http.NewRequestWithContext(ctx, "POST", s.cfg.Hosts.Upload+"/api/v0/block/put?"+q.Encode(), body.pr)
+		if err != nil {
+			body.abort()
+			return nil, err
+		}
+		req.Header.Set("Content-Type", body.contentType)
+		return req, nil
+	}
+
+	res, err := s.send(ctx, true, build)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusMethodNotAllowed {
+		return fmt.Errorf("%w (status %d)", ErrResumeUnsupported, res.StatusCode)
+	}
+	if err := httpx.CheckResponse(res); err != nil {
+		return err
+	}
+
+	var out struct {
+		Key string `json:"Key"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return err
+	}
+	if got, err := unixfs.Parse(out.Key); err != nil || !got.Equal(b.CID) {
+		return fmt.Errorf("block/put returned %s, expected %s", out.Key, b.CID)
+	}
+	return nil
+}
+
+func resumeStatePath(dir, abs string, fi fs.FileInfo, chunkSize int64) (string, error) {
+	if dir == "" {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(cache, "lighthouse-go-sdk", "uploads")
+	}
+	key := fmt.Sprintf("%s\x00%d\x00%d\x00%d", abs, fi.Size(), fi.ModTime().UnixNano(), chunkSize)
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:16])+".json"), nil
+}
+
+// loadResumeState returns the saved state if it still describes the file,
+// or a fresh state otherwise.
+func loadResumeState(file, abs string, fi fs.FileInfo, chunkSize int64) *resumeState {
+	fresh := &resumeState{Path: abs, Size: fi.Size(), ModTime: fi.ModTime(), ChunkSize: chunkSize}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return fresh
+	}
+	var st resumeState
+	if json.Unmarshal(b, &st) != nil ||
+		st.Path != abs || st.Size != fi.Size() || !st.ModTime.Equal(fi.ModTime()) || st.ChunkSize != chunkSize {
+		return fresh
+	}
+	return &st
+}
+
+// saveResumeState writes st atomically so a crash never leaves a torn file.
+func saveResumeState(file string, st *resumeState) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".upload-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
@@ -0,0 +1,133 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+func writeTemp(t *testing.T, data []byte) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(p, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestResumeUploadRemovesParts(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	client := srv.Client()
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	path := writeTemp(t, data)
+
+	res, err := client.Storage().ResumeUpload(ctx, path,
+		schema.WithChunkSize(4096), schema.WithStateDir(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := srv.Files()
+	if len(files) != 1 || files[0].Name != "data.bin" || files[0].CID != res.Hash {
+		t.Errorf("Files() = %+v, want only data.bin at %s", files, res.Hash)
+	}
+
+	rc, _, err := client.Gateway().Get(ctx, res.Hash, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(got, data) {
+		t.Error("downloaded content differs from the file")
+	}
+}
+
+// failNth makes the nth /api/v0/add request fail with a 400, which is not
+// retried, by arming srv.Fail just before it is sent.
+type failNth struct {
+	srv  *lighthousetest.Server
+	n    int
+	seen int
+}
+
+func (f *failNth) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.Path == "/api/v0/add" {
+		if f.seen++; f.seen == f.n {
+			f.srv.Fail(http.MethodPost, "/api/v0/add", http.StatusBadRequest, 1)
+		}
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestResumeUploadAfterInterruption(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	data := bytes.Repeat([]byte("abcdefghij"), 1000)
+	path := writeTemp(t, data)
+	stateDir := t.TempDir()
+	opts := []schema.UploadOption{schema.WithChunkSize(2048), schema.WithStateDir(stateDir)}
+
+	// Chunks 0 and 1 of 5 finish; chunk 2 fails.
+	client := srv.Client(lighthouse.WithHTTPClient(&http.Client{Transport: &failNth{srv: srv, n: 3}}))
+	if _, err := client.Storage().ResumeUpload(ctx, path, opts...); err == nil {
+		t.Fatal("interrupted upload: want an error")
+	}
+	if states, _ := os.ReadDir(stateDir); len(states) != 1 {
+		t.Fatalf("state files after interruption = %d, want 1", len(states))
+	}
+	var parts []string
+	for _, f := range srv.Files() {
+		parts = append(parts, f.Name)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("records after interruption = %q, want the two finished parts", parts)
+	}
+
+	res, err := srv.Client().Storage().ResumeUpload(ctx, path, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hits := srv.Hits(http.MethodPost, "/api/v0/add"); hits != 6 {
+		t.Errorf("add requests = %d, want 3 + 3 with finished chunks not re-sent", hits)
+	}
+	if states, _ := os.ReadDir(stateDir); len(states) != 0 {
+		t.Errorf("state files after completion = %d, want 0", len(states))
+	}
+	files := srv.Files()
+	if len(files) != 1 || files[0].Name != "data.bin" || files[0].CID != res.Hash {
+		t.Errorf("Files() = %+v, want only data.bin at %s", files, res.Hash)
+	}
+
+	rc, _, err := srv.Client().Gateway().Get(ctx, res.Hash, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(got, data) {
+		t.Error("downloaded content differs from the file")
+	}
+}
+
+func TestResumeUploadBlockPutUnsupported(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	srv.Fail(http.MethodPost, "/api/v0/block/put", http.StatusMethodNotAllowed, -1)
+	path := writeTemp(t, bytes.Repeat([]byte("x"), 10000))
+
+	_, err := srv.Client().Storage().ResumeUpload(ctx, path,
+		schema.WithChunkSize(4096), schema.WithStateDir(t.TempDir()))
+	if !errors.Is(err, lighthouse.ErrResumeUnsupported) {
+		t.Errorf("err = %v, want ErrResumeUnsupported", err)
+	}
+}
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+// Source is content Upload can send.
+type Source interface {
+	// Name is the filename sent with the upload.
+	Name() string
+	// Size is the content length in bytes, or -1 if it is not known
+	// up front.
+	Size() int64
+	// Open returns the content from its start. The caller closes it. A
+	// reader that also implements io.Seeker is rewound to retry a failed
+	// attempt; others get a single attempt.
+	Open() (io.ReadCloser, error)
+}
+
+// Upload sends src as a single file, retried per the client's RetryPolicy
+// when the reader it opens is seekable.
+func (s *Service) Upload(ctx context.Context, src Source, opts ...schema.UploadOption) (*schema.UploadResult, error) {
+	rc, err := src.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return s.UploadReader(ctx, src.Name(), src.Size(), rc, opts...)
+}
+
+// FileSource reads a file on disk.
+func FileSource(path string) Source { return fileSource(path) }
+
+type fileSource string
+
+func (f fileSource) Name() string { return filepath.Base(string(f)) }
+
+func (f fileSource) Size() int64 {
+	fi, err := os.Stat(string(f))
+	if err != nil {
+		return -1
+	}
+	return fi.Size()
+}
+
+func (f fileSource) Open() (io.ReadCloser, error) { return os.Open(string(f)) }
+
+// BytesSource uploads b under name.
+func BytesSource(name string, b []byte) Source {
+	return ReaderAtSource(name, bytes.NewReader(b), int64(len(b)))
+}
+
+// StringSource uploads text under name.
+func StringSource(name, text string) Source {
+	return ReaderAtSource(name, strings.NewReader(text), int64(len(text)))
+}
+
+// ReaderAtSource uploads the first size bytes of r under name.
+func ReaderAtSource(name string, r io.ReaderAt, size int64) Source {
+	return readerAtSource{name: name, r: r, size: size}
+}
+
+type readerAtSource struct {
+	name string
+	r    io.ReaderAt
+	size int64
+}
+
+func (s readerAtSource) Name() string { return s.name }
+func (s readerAtSource) Size() int64  { return s.size }
+func (s readerAtSource) Open() (io.ReadCloser, error) {
+	return nopSeekCloser{io.NewSectionReader(s.r, 0, s.size)}, nil
+}
+
+// nopSeekCloser is io.NopCloser that keeps Seek, which postFile needs to
+// rewind between attempts.
+type nopSeekCloser struct{ io.ReadSeeker }
+
+func (nopSeekCloser) Close() error { return nil }
+
+// StreamSource uploads whatever r yields under name. size is its length, or
+// -1 if unknown. A stream can be opened only once, so it is never retried.
+func StreamSource(name string, r io.Reader, size int64) Source {
+	return &streamSource{name: name, r: r, size: size}
+}
+
+var errStreamConsumed = errors.New("storage: stream source already opened")
+
+type streamSource struct {
+	name   string
+	r      io.Reader
+	size   int64
+	mu     sync.Mutex
+	opened bool
+}
+
+func (s *streamSource) Name() string { return s.name }
+func (s *streamSource) Size() int64  { return s.size }
+func (s *streamSource) Open() (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.opened {
+		return nil, errStreamConsumed
+	}
+	s.opened = true
+	// Hide any Seek method of r so the stream is never retried.
+	return io.NopCloser(struct{ io.Reader }{s.r}), nil
+}
+
+// FSSource uploads the file name from fsys. It is rewindable when the
+// opened fs.File implements io.Seeker, as os.DirFS and embed.FS files do.
+func FSSource(fsys fs.FS, name string) Source {
+	return fsSource{fsys: fsys, name: name}
+}
+
+type fsSource struct {
+	fsys fs.FS
+	name string
+}
+
+func (s fsSource) Name() string { return path.Base(s.name) }
+
+func (s fsSource) Size() int64 {
+	fi, err := fs.Stat(s.fsys, s.name)
+	if err != nil || !fi.Mode().IsRegular() {
+		return -1
+	}
+	return fi.Size()
+}
+
+func (s fsSource) Open() (io.ReadCloser, error) { return s.fsys.Open(s.name) }
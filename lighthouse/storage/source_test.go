@@ -0,0 +1,67 @@
+package storage_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/storage"
+)
+
+// Sources whose opened reader can seek are retried; streams are not.
+func TestUploadSourceRetries(t *testing.T) {
+	ctx := context.Background()
+	fsys := fstest.MapFS{"dir/a.txt": {Data: []byte("from an fs.FS")}}
+	for _, tc := range []struct {
+		name  string
+		src   storage.Source
+		retry bool
+	}{
+		{"fs", storage.FSSource(fsys, "dir/a.txt"), true},
+		{"bytes", storage.BytesSource("b.txt", []byte("bytes")), true},
+		{"stream", storage.StreamSource("s.txt", strings.NewReader("stream"), -1), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := lighthousetest.NewServer(t)
+			srv.Fail(http.MethodPost, "/api/v0/add", http.StatusServiceUnavailable, 1)
+			_, err := srv.Client().Storage().Upload(ctx, tc.src)
+			if tc.retry && err != nil {
+				t.Errorf("rewindable source: %v", err)
+			}
+			if !tc.retry && err == nil {
+				t.Error("stream source was retried")
+			}
+			want := 1
+			if tc.retry {
+				want = 2
+			}
+			if hits := srv.Hits(http.MethodPost, "/api/v0/add"); hits != want {
+				t.Errorf("add hits = %d, want %d", hits, want)
+			}
+		})
+	}
+}
+
+// One FSSource may be uploaded from several goroutines at once.
+func TestFSSourceConcurrentUploads(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	client := srv.Client()
+	src := storage.FSSource(fstest.MapFS{"a.txt": {Data: []byte("shared")}}, "a.txt")
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Storage().Upload(ctx, src); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -1,21 +1,22 @@
 package storage
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 
 	"fmt"
 	"io"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
 	"sync/atomic"
 
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/encryption"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/cfg"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/httpx"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/telemetry"
 )
 
 type Service struct {
@@ -48,104 +49,95 @@ func (p *progressReader) Read(b []byte) (int, error) {
 	return n, err
 }
 
-func (s *Service) UploadFile(ctx context.Context, path string, opts ...schema.UploadOption) (*schema.UploadResult, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
+// Close closes the underlying reader so the transport can unblock the
+// multipart writer when a request is abandoned.
+func (p *progressReader) Close() error {
+	if c, ok := p.r.(io.Closer); ok {
+		return c.Close()
 	}
-	defer f.Close()
+	return nil
+}
 
-	stat, err := f.Stat()
-	if err != nil {
-		return nil, err
-	}
-	return s.UploadReader(ctx, filepath.Base(path), stat.Size(), f, opts...)
+func (s *Service) UploadFile(ctx context.Context, path string, opts ...schema.UploadOption) (*schema.UploadResult, error) {
+	return s.Upload(ctx, FileSource(path), opts...)
 }
 
-func (s *Service) UploadReader(ctx context.Context, name string, size int64, r io.Reader, opts ...schema.UploadOption) (*schema.UploadResult, error) {
+// UploadReader uploads size bytes read from r under name. size may be -1
+// when the length is not known up front (e.g. a pipe): the body is then
+// streamed with chunked transfer encoding and WithQuotaCheck only catches an
+// exhausted quota. Progress then counts content bytes sent, with Total -1,
+// and ends with a call where Uploaded and Total are both the final count.
+func (s *Service) UploadReader(ctx context.Context, name string, size int64, r io.Reader, opts ...schema.UploadOption) (_ *schema.UploadResult, err error) {
+	ctx, op := s.h.StartOp(ctx, "storage.UploadReader", telemetry.Int64(telemetry.AttrBytes, size))
+	defer func() { op.End(err) }()
+
 	o := schema.DefaultUploadOptions()
 	for _, opt := range opts {
 		opt(o)
 	}
 
-	pr, pw := io.Pipe()
-	mw := multipart.NewWriter(pw)
-
-	boundary := mw.Boundary()
-
-	headerSize := int64(len(fmt.Sprintf("--%s\r\nContent-Disposition: form-data; name=\"file\"; filename=\"%s\"\r\nContent-Type: application/octet-stream\r\n\r\n", boundary, name)))
-	footerSize := int64(len(fmt.Sprintf("\r\n--%s--\r\n", boundary)))
-	totalSize := headerSize + size + footerSize
-
-	// Goroutine to write multipart data
-	go func() {
-		defer pw.Close()
-		defer mw.Close()
-
-		// file field
-		fw, err := mw.CreateFormFile("file", name)
-		if err != nil {
-			pw.CloseWithError(err)
-			return
+	// Each attempt gets a fresh encryption stream (new salt) and, when
+	// verifying, a fresh local CID computed over the bytes actually sent.
+	wireSize := size
+	var local *cid.Builder
+	if o.VerifyCID {
+		local = cid.NewBuilder(cid.Options{})
+	}
+	if o.EncryptKey != nil {
+		if len(o.EncryptKey) != encryption.KeySize {
+			return nil, encryption.ErrKeySize
 		}
-		buf := make([]byte, 32*1024)
-		for {
-			select {
-			case <-ctx.Done():
-				pw.CloseWithError(ctx.Err())
-				return
-			default:
-			}
-
-			n, err := r.Read(buf)
-			if n > 0 {
-				if _, writeErr := fw.Write(buf[:n]); writeErr != nil {
-					pw.CloseWithError(writeErr)
-					return
-				}
-			}
-			if err == io.EOF {
-				break
-			}
+		if size >= 0 {
+			wireSize = encryption.EncryptedSize(size)
+		}
+	}
+	if o.CheckQuota {
+		need := wireSize
+		if need < 0 {
+			// Nothing to compare against; at least refuse a full account.
+			need = 1
+		}
+		if err := s.checkQuota(ctx, need); err != nil {
+			return nil, err
+		}
+	}
+	wrap := func(src io.Reader) io.Reader {
+		if o.EncryptKey != nil {
+			er, err := encryption.NewEncryptReader(src, o.EncryptKey)
 			if err != nil {
-				pw.CloseWithError(err)
-				return
+				return errReader{err}
 			}
+			src = er
 		}
-	}()
-
-	var uploaded int64
-	progressPipeReader := io.Reader(pr) // Type as io.Reader, not *io.PipeReader
-	if o.OnProgress != nil {
-		progressPipeReader = &progressReader{
-			r:        pr,
-			uploaded: &uploaded,
-			total:    totalSize,
-			onProg:   o.OnProgress,
+		if local != nil {
+			local.Reset()
+			src = io.TeeReader(src, local)
 		}
+		return src
 	}
-	url := s.cfg.Hosts.Upload + "/api/v0/add?cid-version=1"
-	req, err := http.NewRequestWithContext(ctx, "POST", url, progressPipeReader)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", mw.FormDataContentType())
 
-	res, err := s.h.Inject(req)
+	url := s.cfg.Hosts.Upload + "/api/v0/add?cid-version=1"
+	res, totalSize, err := s.postFile(ctx, url, name, wireSize, r, o, wrap)
 	if err != nil {
 		return nil, err
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		b, _ := io.ReadAll(res.Body)
-		return nil, fmt.Errorf("http %d: %s", res.StatusCode, string(b))
-	}
-
 	var result schema.UploadResult
 	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
 		return nil, err
 	}
+	op.SetAttributes(telemetry.String(telemetry.AttrCID, result.Hash))
+	if wireSize < 0 {
+		wireSize = totalSize
+	}
+	op.AddUploaded(wireSize)
+
+	if local != nil {
+		if err := verifyCID(local, result.Hash); err != nil {
+			return nil, err
+		}
+	}
 
 	if o.OnProgress != nil {
 		o.OnProgress(schema.Progress{
@@ -156,12 +148,105 @@ func (s *Service) UploadReader(ctx context.Context, name string, size int64, r i
 	return &result, nil
 }
 
+// postFile streams r as the single "file" field of a multipart POST to url
+// and returns the successful response together with the request body size,
+// or for size -1 the number of content bytes sent. Progress is reported in
+// the same unit.
+// Readers that can be rewound (files, bytes.Reader, ...) are retried per the
+// client's RetryPolicy; plain streams get a single attempt. wrap, if set, is
+// applied to the source on every attempt.
+func (s *Service) postFile(ctx context.Context, url, name string, size int64, r io.Reader, o *schema.UploadOptions, wrap func(io.Reader) io.Reader) (*http.Response, int64, error) {
+	rs, rewindable := r.(io.ReadSeeker)
+	var start int64
+	if rewindable {
+		pos, err := rs.Seek(0, io.SeekCurrent)
+		if err != nil {
+			rewindable = false
+		}
+		start = pos
+	}
+
+	s.h.Logger().DebugContext(ctx, "lighthouse upload starting",
+		slog.String("name", name),
+		slog.Int64("size", size),
+		slog.Bool("retryable", rewindable))
+
+	var (
+		body      *multipartBody
+		totalSize int64
+		uploaded  *int64 // body bytes read by the current attempt
+	)
+	build := func(attempt int) (*http.Request, error) {
+		if attempt > 0 {
+			body.abort()
+			if _, err := rs.Seek(start, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+		src := r
+		if wrap != nil {
+			src = wrap(r)
+		}
+		uploaded = new(int64)
+		if size < 0 {
+			// The framing size is unknowable too; count content only.
+			src = &progressReader{r: src, uploaded: uploaded, total: -1, onProg: o.OnProgress}
+		}
+		body = newMultipartBody(func(mw *multipart.Writer) error {
+			fw, err := createFilePart(mw, name)
+			if err != nil {
+				return err
+			}
+			return copyContext(ctx, fw, src)
+		})
+		totalSize = -1
+		var bodyReader io.Reader = body.pr
+		if size >= 0 {
+			headerSize := int64(len(fmt.Sprintf("--%s\r\nContent-Disposition: form-data; name=\"file\"; filename=\"%s\"\r\nContent-Type: application/octet-stream\r\n\r\n", body.boundary, quoteEscaper.Replace(name))))
+			footerSize := int64(len(fmt.Sprintf("\r\n--%s--\r\n", body.boundary)))
+			totalSize = headerSize + size + footerSize
+			bodyReader = &progressReader{
+				r:        body.pr,
+				uploaded: uploaded,
+				total:    totalSize,
+				onProg:   o.OnProgress,
+			}
+		}
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bodyReader)
+		if err != nil {
+			body.abort()
+			return nil, err
+		}
+		// The multipart body is produced on the fly, so it is always sent
+		// with chunked transfer encoding.
+		req.ContentLength = -1
+		req.Header.Set("Content-Type", body.contentType)
+		return req, nil
+	}
+
+	res, err := s.send(ctx, rewindable, build)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := httpx.CheckResponse(res); err != nil {
+		res.Body.Close()
+		return nil, 0, err
+	}
+	if totalSize < 0 {
+		totalSize = atomic.LoadInt64(uploaded)
+	}
+	return res, totalSize, nil
+}
+
+// errReader fails the multipart stream with err.
+type errReader struct{ err error }
+
+func (e errReader) Read([]byte) (int, error) { return 0, e.err }
+
 func (s *Service) UploadText(ctx context.Context, filename, text string, opts ...schema.UploadOption) (*schema.UploadResult, error) {
-	r := bytes.NewReader([]byte(text))
-	return s.UploadReader(ctx, filename, int64(len(text)), r, opts...)
+	return s.Upload(ctx, StringSource(filename, text), opts...)
 }
 
 func (s *Service) UploadBuffer(ctx context.Context, filename string, data []byte, opts ...schema.UploadOption) (*schema.UploadResult, error) {
-	r := bytes.NewReader(data)
-	return s.UploadReader(ctx, filename, int64(len(data)), r, opts...)
+	return s.Upload(ctx, BytesSource(filename, data), opts...)
 }
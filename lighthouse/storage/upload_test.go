@@ -0,0 +1,90 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/cid"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/lighthousetest"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/storage"
+)
+
+// Progress for an unknown-length stream counts content bytes only and ends
+// with the real total.
+func TestUploadStreamProgress(t *testing.T) {
+	srv := lighthousetest.NewServer(t)
+	text := strings.Repeat("stream ", 50000)
+
+	var got []schema.Progress
+	src := storage.StreamSource("s.txt", strings.NewReader(text), -1)
+	_, err := srv.Client().Storage().Upload(context.Background(), src,
+		schema.WithProgress(func(p schema.Progress) { got = append(got, p) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) < 2 {
+		t.Fatalf("got %d progress updates, want several", len(got))
+	}
+	n := int64(len(text))
+	for _, p := range got[:len(got)-1] {
+		if p.Total != -1 || p.Uploaded > n {
+			t.Errorf("intermediate progress %+v, want Total -1 and at most %d bytes", p, n)
+		}
+	}
+	if last := got[len(got)-1]; last.Uploaded != n || last.Total != n {
+		t.Errorf("final progress %+v, want %d/%d", last, n, n)
+	}
+}
+
+// swapHash replaces the CID in /api/v0/add responses with another one.
+type swapHash struct{ hash string }
+
+func (s swapHash) RoundTrip(r *http.Request) (*http.Response, error) {
+	res, err := http.DefaultTransport.RoundTrip(r)
+	if err != nil || r.URL.Path != "/api/v0/add" || res.StatusCode != http.StatusOK {
+		return res, err
+	}
+	b, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	b = []byte(strings.Replace(string(b), `"Hash":"`, `"Hash":"`+s.hash+`","Was":"`, 1))
+	res.Body = io.NopCloser(bytes.NewReader(b))
+	res.ContentLength = int64(len(b))
+	return res, nil
+}
+
+func TestUploadVerifyCID(t *testing.T) {
+	ctx := context.Background()
+	srv := lighthousetest.NewServer(t)
+	// Large enough to be chunked into several blocks.
+	text := strings.Repeat("verify ", 100000)
+	want, _ := cid.Compute(strings.NewReader(text))
+
+	res, err := srv.Client().Storage().UploadText(ctx, "a.txt", text, schema.WithVerifyCID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Hash != want.String() {
+		t.Errorf("Hash = %s, want %s", res.Hash, want)
+	}
+
+	other, _ := cid.Compute(strings.NewReader("something else"))
+	client := srv.Client(lighthouse.WithHTTPClient(&http.Client{Transport: swapHash{other.String()}}))
+	_, err = client.Storage().UploadText(ctx, "a.txt", text, schema.WithVerifyCID())
+	if !errors.Is(err, lighthouse.ErrCIDMismatch) || !strings.Contains(err.Error(), other.String()) {
+		t.Errorf("err = %v, want ErrCIDMismatch naming %s", err, other)
+	}
+	// Without verification the server's answer is taken as is.
+	if res, err := client.Storage().UploadText(ctx, "a.txt", text); err != nil || res.Hash != other.String() {
+		t.Errorf("unverified upload = %v, %v; want %s", res, err, other)
+	}
+}
@@ -0,0 +1,84 @@
+// Package telemetry defines the small tracing and metrics surface the SDK
+// emits to. It mirrors the shape of OpenTelemetry so adapters are thin (see
+// the lhotel module); the defaults are no-ops.
+package telemetry
+
+import "context"
+
+// Attribute keys set on spans and metrics.
+const (
+	AttrOperation  = "lighthouse.operation"
+	AttrCID        = "lighthouse.cid"
+	AttrBytes      = "lighthouse.bytes"
+	AttrRetryCount = "lighthouse.retry_count"
+	AttrHTTPStatus = "http.response.status_code"
+)
+
+// Metric names recorded by the SDK.
+const (
+	MetricOperationDuration = "lighthouse.operation.duration" // histogram, seconds
+	MetricUploadedBytes     = "lighthouse.upload.bytes"       // counter, bytes
+	MetricRetries           = "lighthouse.http.retries"       // counter
+)
+
+// Attr is a key/value pair. Value is a string, int64, float64 or bool.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+func String(k, v string) Attr      { return Attr{Key: k, Value: v} }
+func Int64(k string, v int64) Attr { return Attr{Key: k, Value: v} }
+func Int(k string, v int) Attr     { return Attr{Key: k, Value: int64(v)} }
+func Bool(k string, v bool) Attr   { return Attr{Key: k, Value: v} }
+
+type Span interface {
+	SetAttributes(attrs ...Attr)
+	RecordError(err error)
+	End()
+}
+
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span)
+}
+
+type Int64Counter interface {
+	Add(ctx context.Context, n int64, attrs ...Attr)
+}
+
+type Float64Histogram interface {
+	Record(ctx context.Context, v float64, attrs ...Attr)
+}
+
+// Meter creates instruments. It is called once per instrument when the
+// client is built.
+type Meter interface {
+	Int64Counter(name, unit, description string) Int64Counter
+	Float64Histogram(name, unit, description string) Float64Histogram
+}
+
+// NoopTracer discards all spans.
+type NoopTracer struct{}
+
+func (NoopTracer) Start(ctx context.Context, _ string, _ ...Attr) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attr) {}
+func (noopSpan) RecordError(error)     {}
+func (noopSpan) End()                  {}
+
+// NoopMeter discards all measurements.
+type NoopMeter struct{}
+
+func (NoopMeter) Int64Counter(string, string, string) Int64Counter { return noopInstrument{} }
+func (NoopMeter) Float64Histogram(string, string, string) Float64Histogram {
+	return noopInstrument{}
+}
+
+type noopInstrument struct{}
+
+func (noopInstrument) Add(context.Context, int64, ...Attr)      {}
+func (noopInstrument) Record(context.Context, float64, ...Attr) {}
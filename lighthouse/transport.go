@@ -0,0 +1,35 @@
+package lighthouse
+
+import (
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/credentials"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/httpx"
+)
+
+// CredentialsProvider supplies the API key for each request.
+type CredentialsProvider = credentials.Provider
+
+// RetryPolicy controls retries of idempotent API calls and of uploads whose
+// source can be rewound.
+type RetryPolicy = httpx.RetryPolicy
+
+// DefaultRetryableStatus lists the status codes retried by default.
+var DefaultRetryableStatus = httpx.DefaultRetryableStatus
+
+// DefaultRetryPolicy returns the policy used when WithRetryPolicy is not given.
+func DefaultRetryPolicy() RetryPolicy { return httpx.DefaultRetryPolicy() }
+
+// NoRetry returns a policy that never retries.
+func NoRetry() RetryPolicy { return httpx.NoRetry() }
+
+// RateLimit configures a client-side token bucket (RPS tokens/second, up to Burst).
+type RateLimit = httpx.RateLimit
+
+// RoundTripFunc sends a single HTTP request.
+type RoundTripFunc = httpx.RoundTripFunc
+
+// Middleware wraps every request the Client sends, after default headers are
+// applied and rate limiting has admitted it. Retries pass through it again.
+type Middleware = httpx.Middleware
+
+// MiddlewareFunc adapts a function to Middleware.
+type MiddlewareFunc = httpx.MiddlewareFunc
@@ -3,17 +3,32 @@ package lighthouse
 import (
 	"context"
 	"io"
+	"iter"
 
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/auth"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/gateway"
 	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/storage"
 )
 
 type StorageService interface {
 	UploadFile(ctx context.Context, path string, opts ...schema.UploadOption) (*schema.UploadResult, error)
 	UploadReader(ctx context.Context, name string, size int64, r io.Reader, opts ...schema.UploadOption) (*schema.UploadResult, error)
+	UploadText(ctx context.Context, filename, text string, opts ...schema.UploadOption) (*schema.UploadResult, error)
+	UploadBuffer(ctx context.Context, filename string, data []byte, opts ...schema.UploadOption) (*schema.UploadResult, error)
+	Upload(ctx context.Context, src UploadSource, opts ...schema.UploadOption) (*schema.UploadResult, error)
+	ResumeUpload(ctx context.Context, path string, opts ...schema.UploadOption) (*schema.UploadResult, error)
+	UploadCAR(ctx context.Context, path string, opts ...schema.UploadOption) (*schema.UploadResult, error)
+	UploadDirectory(ctx context.Context, root string, opts ...schema.UploadOption) (*schema.DirectoryUploadResult, error)
 }
 
+// UploadSource is content for StorageService.Upload; see storage.FileSource,
+// BytesSource, StringSource, ReaderAtSource, StreamSource and FSSource.
+type UploadSource = storage.Source
+
 type FilesService interface {
 	List(ctx context.Context, lastKey *string) (*schema.FileList, error)
+	All(ctx context.Context, opts ...schema.ListOption) iter.Seq2[schema.FileEntry, error]
 	Info(ctx context.Context, cid string) (*schema.FileInfo, error)
 	Pin(ctx context.Context, cid, name string) error
 	Delete(ctx context.Context, id string) error
@@ -21,6 +36,7 @@ type FilesService interface {
 
 type DealsService interface {
 	Status(ctx context.Context, cid string) ([]schema.DealStatus, error)
+	Watch(ctx context.Context, cid string, opts ...schema.WatchOption) iter.Seq2[schema.DealUpdate, error]
 }
 
 type IPNSService interface {
@@ -29,3 +45,32 @@ type IPNSService interface {
 	ListKeys(ctx context.Context) ([]schema.IPNSRecord, error) // Make sure this matches
 	RemoveKey(ctx context.Context, keyName string) (*schema.IPNSRemoveResponse, error)
 }
+
+type AuthService interface {
+	Message(ctx context.Context, address string) (string, error)
+	ExchangeSignature(ctx context.Context, address, signature string) (string, error)
+	CreateAPIKey(ctx context.Context, signer Signer) (string, error)
+}
+
+// Signer signs the auth message for a wallet; see auth.NewKeySigner.
+type Signer = auth.Signer
+
+type UserService interface {
+	Usage(ctx context.Context) (*schema.Usage, error)
+}
+
+type GatewayService interface {
+	Get(ctx context.Context, cid, subpath string, opts ...schema.DownloadOption) (io.ReadCloser, *schema.ContentInfo, error)
+	GetVerified(ctx context.Context, cid, subpath string, opts ...schema.DownloadOption) (io.ReadCloser, *schema.ContentInfo, error)
+	GetRange(ctx context.Context, cid string, offset, length int64) (io.ReadCloser, *schema.ContentInfo, error)
+	Download(ctx context.Context, cid, dstPath string, opts ...schema.DownloadOption) (*schema.ContentInfo, error)
+	NewReader(ctx context.Context, cid string) (*GatewayReader, error)
+	FS(ctx context.Context, cid string) (*GatewayFS, error)
+}
+
+// GatewayReader is an io.ReadSeeker and io.ReaderAt over gateway content.
+type GatewayReader = gateway.Reader
+
+// GatewayFS is a read-only fs.FS, fs.ReadDirFS and fs.StatFS over a UnixFS
+// directory CID.
+type GatewayFS = gateway.FS
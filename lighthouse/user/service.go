@@ -0,0 +1,32 @@
+package user
+
+import (
+	"context"
+
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/cfg"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/internal/httpx"
+	"github.com/lighthouse-web3/lighthouse-go-sdk/lighthouse/schema"
+)
+
+type Service struct {
+	h   *httpx.Client
+	cfg cfg.Config
+}
+
+func New(h *httpx.Client, c cfg.Config) *Service {
+	return &Service{h: h, cfg: c}
+}
+
+// Usage returns the account's storage limit and the bytes used so far.
+func (s *Service) Usage(ctx context.Context) (_ *schema.Usage, err error) {
+	ctx, op := s.h.StartOp(ctx, "user.Usage")
+	defer func() { op.End(err) }()
+
+	u := s.cfg.Hosts.API + "/api/user/user_data_usage"
+	var out schema.Usage
+	_, err = s.h.WriteJSON(ctx, "GET", u, nil, &out)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}